@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+// KubernikusControlPlaneFinalizer allows the controller to terminate the kluster
+// in kubernikus before the KubernikusControlPlane is removed.
+const KubernikusControlPlaneFinalizer = "kubernikus.controlplane.cluster.x-k8s.io"
+
+// SecretLabel marks the secrets watched by the controller, the kubeconfig, CA and service account secrets
+// of the clusters and the credentials secrets. Other secrets are not cached. The controller labels
+// credentials secrets once it read them, label new ones to have them picked up right away.
+const SecretLabel = "kubernikus.controlplane.cluster.x-k8s.io/secret"
+
+const (
+	// DeletingCondition reports the progress of the kluster termination.
+	DeletingCondition = "Deleting"
+
+	// KlusterTerminatingReason is used while kubernikus is terminating the kluster.
+	KlusterTerminatingReason = "KlusterTerminating"
+	// DeletionFailedReason is used when the kluster could not be terminated.
+	DeletionFailedReason = "DeletionFailed"
+	// DeletionBlockedReason is used when the kluster can not be terminated as the credentials are missing or invalid.
+	DeletionBlockedReason = "DeletionBlocked"
+)
+
+const (
+	// ImmutableFieldsInSyncCondition reports whether the fields kubernikus can not update on a running
+	// kluster match the spec of the KubernikusControlPlane.
+	ImmutableFieldsInSyncCondition = "ImmutableFieldsInSync"
+
+	// ImmutableFieldsInSyncReason is used when the kluster matches the spec.
+	ImmutableFieldsInSyncReason = "InSync"
+	// ImmutableFieldsDriftedReason is used when the kluster differs from the spec in immutable fields.
+	ImmutableFieldsDriftedReason = "Drifted"
+)
+
+const (
+	// ReadyCondition summarizes the CredentialsValid, KlusterProvisioned, ControlPlaneReady,
+	// KubeconfigAvailable and CertificatesAvailable conditions.
+	ReadyCondition = "Ready"
+
+	// ReadyReason is used when all summarized conditions are true.
+	ReadyReason = "Ready"
+	// NotReadyReason is used when at least one summarized condition is not true.
+	NotReadyReason = "NotReady"
+)
+
+const (
+	// CredentialsValidCondition reports whether the controller can authenticate against kubernikus.
+	CredentialsValidCondition = "CredentialsValid"
+
+	// CredentialsValidReason is used when kubernikus accepted the credentials.
+	CredentialsValidReason = "Valid"
+	// CredentialsSecretNotFoundReason is used when the secret holding the credentials does not exist.
+	CredentialsSecretNotFoundReason = "SecretNotFound"
+	// InvalidCredentialsReason is used when the secret lacks keys or contains malformed values.
+	InvalidCredentialsReason = "InvalidCredentials"
+	// IdentityNotFoundReason is used when the referenced KubernikusIdentity does not exist.
+	IdentityNotFoundReason = "IdentityNotFound"
+	// NamespaceNotAllowedReason is used when the KubernikusIdentity does not allow the namespace of the control plane.
+	NamespaceNotAllowedReason = "NamespaceNotAllowed"
+	// AuthenticationFailedReason is used when kubernikus or its auth service rejected the credentials.
+	AuthenticationFailedReason = "AuthenticationFailed"
+)
+
+const (
+	// KlusterProvisionedCondition reports whether the kluster exists in kubernikus and has been set up.
+	KlusterProvisionedCondition = "KlusterProvisioned"
+
+	// KlusterProvisionedReason is used when the kluster has left the Pending and Creating phases.
+	KlusterProvisionedReason = "Provisioned"
+	// KlusterProvisioningReason is used while kubernikus is creating the kluster.
+	KlusterProvisioningReason = "Provisioning"
+	// KlusterProvisioningFailedReason is used when the kluster could not be created or updated.
+	KlusterProvisioningFailedReason = "ProvisioningFailed"
+	// KlusterNotFoundReason is used when a kluster which existed before is missing in kubernikus.
+	KlusterNotFoundReason = "KlusterNotFound"
+)
+
+const (
+	// ControlPlaneReadyCondition reports whether the kluster is in the Running phase.
+	ControlPlaneReadyCondition = "ControlPlaneReady"
+
+	// KlusterRunningReason is used when the kluster is running.
+	KlusterRunningReason = "Running"
+	// KlusterNotRunningReason is used when the kluster is in any other phase.
+	KlusterNotRunningReason = "NotRunning"
+	// KlusterStatusUnknownReason is used when the status of the kluster could not be read from kubernikus.
+	KlusterStatusUnknownReason = "StatusUnknown"
+)
+
+const (
+	// KubeconfigAvailableCondition reports whether the kubeconfig secret of the cluster exists.
+	KubeconfigAvailableCondition = "KubeconfigAvailable"
+
+	// KubeconfigAvailableReason is used when the kubeconfig secret is present and valid.
+	KubeconfigAvailableReason = "Available"
+	// KubeconfigFailedReason is used when the kubeconfig secret could not be created or rotated.
+	KubeconfigFailedReason = "KubeconfigFailed"
+)
+
+const (
+	// CertificatesAvailableCondition reports whether the cluster CA and service account secrets exist.
+	CertificatesAvailableCondition = "CertificatesAvailable"
+
+	// CertificatesAvailableReason is used when the certificate secrets are present.
+	CertificatesAvailableReason = "Available"
+	// CertificatesFailedReason is used when the certificate secrets could not be created.
+	CertificatesFailedReason = "CertificatesFailed"
+)
+
+const (
+	// UpgradeInProgressCondition reports whether the kluster is being upgraded to the version of the spec.
+	UpgradeInProgressCondition = "UpgradeInProgress"
+
+	// UpgradingReason is used while the kluster is upgraded.
+	UpgradingReason = "Upgrading"
+	// UpToDateReason is used when the kluster runs the version of the spec.
+	UpToDateReason = "UpToDate"
+)
+
+const (
+	// PausedCondition is true while the owner cluster or the control plane is paused.
+	// The controller does not call kubernikus until it is false again.
+	PausedCondition = "Paused"
+
+	// PausedReason is used when the cluster has spec.paused set or the control plane the cluster.x-k8s.io/paused annotation.
+	PausedReason = "Paused"
+	// NotPausedReason is used when neither the cluster nor the control plane is paused.
+	NotPausedReason = "NotPaused"
+)
+
+// WaitingForControlPlaneReason is used for conditions which can only be satisfied once the kluster is running.
+const WaitingForControlPlaneReason = "WaitingForControlPlane"
+
+// Conditions of the Cluster API v1beta2 contract. Cluster API reads them from the control plane
+// to compute the conditions of the Cluster.
+const (
+	// AvailableCondition is true when the api server of the kluster serves requests.
+	AvailableCondition = "Available"
+
+	// AvailableReason is used when the kluster is running or being upgraded.
+	AvailableReason = "Available"
+	// NotAvailableReason is used when the kluster has not been set up or is being terminated.
+	NotAvailableReason = "NotAvailable"
+
+	// InitializedCondition is true once the control plane has been initialized.
+	// Like status.initialization.controlPlaneInitialized it is never reset.
+	InitializedCondition = "Initialized"
+
+	// InitializedReason is used when the kluster has been running at least once.
+	InitializedReason = "Initialized"
+	// NotInitializedReason is used while the kluster is set up.
+	NotInitializedReason = "NotInitialized"
+
+	// RollingOutCondition is true while kubernikus rolls out a new version of the kluster.
+	RollingOutCondition = "RollingOut"
+
+	// RollingOutReason is used while the kluster is upgraded.
+	RollingOutReason = "RollingOut"
+	// NotRollingOutReason is used when the kluster runs the version of the spec.
+	NotRollingOutReason = "NotRollingOut"
+)
@@ -1,6 +1,9 @@
 // SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
 // SPDX-License-Identifier: Apache-2.0
 
+// Package v1alpha1 contains API Schema definitions for the controlplane v1alpha1 API group
+// +kubebuilder:object:generate=true
+// +groupName=controlplane.cluster.x-k8s.io
 package v1alpha1
 
 import (
@@ -4,6 +4,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -17,6 +18,18 @@ type KubernikusControlPlaneSpec struct {
 
 	Version string `json:"version"`
 
+	// CredentialsRef references the secret holding the kubernikus credentials in the namespace of the
+	// KubernikusControlPlane. The secret can be shared by many control planes.
+	// If it is not set, the secret named after the owner cluster is used.
+	// +optional
+	CredentialsRef *corev1.LocalObjectReference `json:"credentialsRef,omitempty"`
+
+	// IdentityRef references a KubernikusIdentity providing the kubernikus credentials.
+	// The namespace of the KubernikusControlPlane has to be allowed by the identity.
+	// It can not be combined with credentialsRef.
+	// +optional
+	IdentityRef *KubernikusIdentityReference `json:"identityRef,omitempty"`
+
 	ServiceCidr string `json:"serviceCidr,omitempty"`
 	ClusterCidr string `json:"clusterCidr,omitempty"`
 
@@ -26,39 +39,111 @@ type KubernikusControlPlaneSpec struct {
 
 	Backup string `json:"backup,omitempty"`
 
-	CustomCNI bool `json:"customCNI,omitempty"`
+	// CustomCNI disables the CNI deployed by kubernikus, so one can be installed into the cluster.
+	// +kubebuilder:default=true
+	// +optional
+	CustomCNI *bool `json:"customCNI,omitempty"`
 
 	DnsAddress string `json:"dnsAddress,omitempty"`
 	DnsDomain  string `json:"dnsDomain,omitempty"`
 
-	SeedKubeadm bool `json:"seedKubeadm,omitempty"`
+	// SeedKubeadm seeds the resources required to join nodes with kubeadm.
+	// +kubebuilder:default=true
+	// +optional
+	SeedKubeadm *bool `json:"seedKubeadm,omitempty"`
 
 	SSHPublicKey string `json:"sshPublicKey,omitempty"`
 
-	Oidc  *OIDC  `json:"oidc,omitempty"`
+	Oidc *OIDC `json:"oidc,omitempty"`
+
+	// Audit selects the sink for the audit log of the api server.
+	// +kubebuilder:validation:Enum=elasticsearch;swift;http;stdout
+	// +kubebuilder:default=stdout
+	// +optional
 	Audit string `json:"audit,omitempty"`
+
+	// Dex deploys dex into the kluster.
+	// +kubebuilder:default=false
+	// +optional
+	Dex *bool `json:"dex,omitempty"`
+	// Dashboard deploys the kubernetes dashboard into the kluster, it requires dex.
+	// +kubebuilder:default=false
+	// +optional
+	Dashboard *bool `json:"dashboard,omitempty"`
+
+	// DeletionPolicy decides what happens to the kluster when the KubernikusControlPlane is deleted.
+	// If the kluster can not be terminated because the credentials are missing or invalid, the Deleting
+	// condition reports DeletionBlocked. Removing the finalizer kubernikus.controlplane.cluster.x-k8s.io
+	// by hand then releases the KubernikusControlPlane and leaves the kluster in kubernikus.
+	// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
+// DeletionPolicy describes how the kluster is handled on deletion of the KubernikusControlPlane.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete terminates the kluster and removes its secrets.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the kluster untouched in kubernikus and only removes its secrets.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetain terminates the kluster but keeps its secrets.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
 // KubernikusControlPlaneStatus defines the observed state of KubernikusControlPlane
 type KubernikusControlPlaneStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// Initialized is true once the kluster has been running, like initialization.controlPlaneInitialized.
+	// It is used by the Cluster API v1beta1 contract.
 	Initialized bool `json:"initialized"`
 	Ready       bool `json:"ready"`
 
-	FailureReason  string `json:"failureReason,omitempty"`
+	// Initialization provides observations of the control plane initialization process,
+	// as expected by the Cluster API v1beta2 contract.
+	// +optional
+	Initialization *KubernikusControlPlaneInitializationStatus `json:"initialization,omitempty"`
+
+	// FailureReason classifies an error of the kubernikus api which retrying will not fix,
+	// like an invalid spec, an exceeded quota or a name conflict.
+	// +optional
+	FailureReason string `json:"failureReason,omitempty"`
+	// FailureMessage is the error message returned by kubernikus for the FailureReason.
+	// +optional
 	FailureMessage string `json:"failureMessage,omitempty"`
 
 	Version    string             `json:"version"`
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
+	// Phase is the phase of the kluster as reported by kubernikus.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// UpdatedFields lists the spec fields sent to kubernikus with the last update of the kluster.
+	// +optional
+	UpdatedFields []string `json:"updatedFields,omitempty"`
+	// LastUpdateTime is the time of the last update of the kluster.
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
 	// ExternalManagedControlPlane indicates to Cluster API that the Control Plane
 	// is externally managed by Kubernikus.
 	// +kubebuilder:default=true
 	ExternalManagedControlPlane *bool `json:"externalManagedControlPlane"`
 }
 
+// KubernikusControlPlaneInitializationStatus provides observations of the control plane initialization process.
+type KubernikusControlPlaneInitializationStatus struct {
+	// ControlPlaneInitialized is true once the kluster has been running and its api server accepted requests.
+	// It is never reset, as the Cluster API contract expects.
+	// +optional
+	ControlPlaneInitialized *bool `json:"controlPlaneInitialized,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// KubernikusControlPlaneTemplateSpec defines the desired state of KubernikusControlPlaneTemplate
+type KubernikusControlPlaneTemplateSpec struct {
+	Template KubernikusControlPlaneTemplateResource `json:"template"`
+}
+
+// KubernikusControlPlaneTemplateResource describes the data needed to create a KubernikusControlPlane from a template.
+type KubernikusControlPlaneTemplateResource struct {
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	ObjectMeta capiv1beta1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KubernikusControlPlaneTemplateResourceSpec `json:"spec"`
+}
+
+// KubernikusControlPlaneTemplateResourceSpec defines the desired state of a KubernikusControlPlane created from a template.
+// It mirrors KubernikusControlPlaneSpec without the version, which is set by the cluster topology.
+type KubernikusControlPlaneTemplateResourceSpec struct {
+	// CredentialsRef references the secret holding the kubernikus credentials in the namespace of the
+	// KubernikusControlPlane. The secret can be shared by many control planes.
+	// If it is not set, the secret named after the owner cluster is used.
+	// +optional
+	CredentialsRef *corev1.LocalObjectReference `json:"credentialsRef,omitempty"`
+
+	// IdentityRef references a KubernikusIdentity providing the kubernikus credentials.
+	// The namespace of the KubernikusControlPlane has to be allowed by the identity.
+	// It can not be combined with credentialsRef.
+	// +optional
+	IdentityRef *KubernikusIdentityReference `json:"identityRef,omitempty"`
+
+	ServiceCidr string `json:"serviceCidr,omitempty"`
+	ClusterCidr string `json:"clusterCidr,omitempty"`
+
+	AdvertiseAddress            string `json:"advertiseAddress,omitempty"`
+	AdvertisePort               int64  `json:"advertisePort,omitempty"`
+	AuthenticationConfiguration string `json:"authenticationConfiguration,omitempty"`
+
+	Backup string `json:"backup,omitempty"`
+
+	// CustomCNI disables the CNI deployed by kubernikus, so one can be installed into the cluster.
+	// +kubebuilder:default=true
+	// +optional
+	CustomCNI *bool `json:"customCNI,omitempty"`
+
+	DnsAddress string `json:"dnsAddress,omitempty"`
+	DnsDomain  string `json:"dnsDomain,omitempty"`
+
+	// SeedKubeadm seeds the resources required to join nodes with kubeadm.
+	// +kubebuilder:default=true
+	// +optional
+	SeedKubeadm *bool `json:"seedKubeadm,omitempty"`
+
+	SSHPublicKey string `json:"sshPublicKey,omitempty"`
+
+	Oidc *OIDC `json:"oidc,omitempty"`
+
+	// Audit selects the sink for the audit log of the api server.
+	// +kubebuilder:validation:Enum=elasticsearch;swift;http;stdout
+	// +kubebuilder:default=stdout
+	// +optional
+	Audit string `json:"audit,omitempty"`
+
+	// Dex deploys dex into the kluster.
+	// +kubebuilder:default=false
+	// +optional
+	Dex *bool `json:"dex,omitempty"`
+	// Dashboard deploys the kubernetes dashboard into the kluster, it requires dex.
+	// +kubebuilder:default=false
+	// +optional
+	Dashboard *bool `json:"dashboard,omitempty"`
+
+	// DeletionPolicy decides what happens to the kluster when the KubernikusControlPlane is deleted.
+	// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:categories=cluster-api
+
+// KubernikusControlPlaneTemplate is the Schema for the kubernikuscontrolplanetemplates API
+type KubernikusControlPlaneTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KubernikusControlPlaneTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KubernikusControlPlaneTemplateList contains a list of KubernikusControlPlaneTemplate
+type KubernikusControlPlaneTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubernikusControlPlaneTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubernikusControlPlaneTemplate{}, &KubernikusControlPlaneTemplateList{})
+}
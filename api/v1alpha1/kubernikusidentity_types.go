@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KubernikusIdentitySpec defines the credentials and the namespaces allowed to use them.
+type KubernikusIdentitySpec struct {
+	// SecretRef is the name of the secret holding the kubernikus credentials.
+	// The secret has to be in the namespace of the controller.
+	// +kubebuilder:validation:MinLength=1
+	SecretRef string `json:"secretRef"`
+
+	// AllowedNamespaces selects the namespaces of the KubernikusControlPlanes allowed to use this identity.
+	// An empty object allows all namespaces, if it is not set no namespace is allowed.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// AllowedNamespaces selects namespaces either by name or with a label selector.
+// A namespace is allowed if it is matched by either of them.
+type AllowedNamespaces struct {
+	// NamespaceList is a list of namespace names.
+	// +optional
+	NamespaceList []string `json:"list,omitempty"`
+
+	// Selector is a label selector matching namespaces.
+	// An empty selector matches all namespaces.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// KubernikusIdentityReference references a KubernikusIdentity.
+type KubernikusIdentityReference struct {
+	// Name of the KubernikusIdentity.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,categories=cluster-api
+
+// KubernikusIdentity is the Schema for the kubernikusidentities API.
+// It shares kubernikus credentials owned by the platform with control planes in other namespaces.
+type KubernikusIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KubernikusIdentitySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KubernikusIdentityList contains a list of KubernikusIdentity
+type KubernikusIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubernikusIdentity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubernikusIdentity{}, &KubernikusIdentityList{})
+}
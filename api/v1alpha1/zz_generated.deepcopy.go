@@ -4,10 +4,36 @@
 package v1alpha1
 
 import (
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedNamespaces) DeepCopyInto(out *AllowedNamespaces) {
+	*out = *in
+	if in.NamespaceList != nil {
+		in, out := &in.NamespaceList, &out.NamespaceList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedNamespaces.
+func (in *AllowedNamespaces) DeepCopy() *AllowedNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernikusControlPlane) DeepCopyInto(out *KubernikusControlPlane) {
 	*out = *in
@@ -35,6 +61,26 @@ func (in *KubernikusControlPlane) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusControlPlaneInitializationStatus) DeepCopyInto(out *KubernikusControlPlaneInitializationStatus) {
+	*out = *in
+	if in.ControlPlaneInitialized != nil {
+		in, out := &in.ControlPlaneInitialized, &out.ControlPlaneInitialized
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusControlPlaneInitializationStatus.
+func (in *KubernikusControlPlaneInitializationStatus) DeepCopy() *KubernikusControlPlaneInitializationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusControlPlaneInitializationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernikusControlPlaneList) DeepCopyInto(out *KubernikusControlPlaneList) {
 	*out = *in
@@ -70,11 +116,41 @@ func (in *KubernikusControlPlaneList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernikusControlPlaneSpec) DeepCopyInto(out *KubernikusControlPlaneSpec) {
 	*out = *in
+	if in.CredentialsRef != nil {
+		in, out := &in.CredentialsRef, &out.CredentialsRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(KubernikusIdentityReference)
+		**out = **in
+	}
+	if in.CustomCNI != nil {
+		in, out := &in.CustomCNI, &out.CustomCNI
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SeedKubeadm != nil {
+		in, out := &in.SeedKubeadm, &out.SeedKubeadm
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Oidc != nil {
 		in, out := &in.Oidc, &out.Oidc
 		*out = new(OIDC)
 		**out = **in
 	}
+	if in.Dex != nil {
+		in, out := &in.Dex, &out.Dex
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Dashboard != nil {
+		in, out := &in.Dashboard, &out.Dashboard
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusControlPlaneSpec.
@@ -90,13 +166,27 @@ func (in *KubernikusControlPlaneSpec) DeepCopy() *KubernikusControlPlaneSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernikusControlPlaneStatus) DeepCopyInto(out *KubernikusControlPlaneStatus) {
 	*out = *in
+	if in.Initialization != nil {
+		in, out := &in.Initialization, &out.Initialization
+		*out = new(KubernikusControlPlaneInitializationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.UpdatedFields != nil {
+		in, out := &in.UpdatedFields, &out.UpdatedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
 	if in.ExternalManagedControlPlane != nil {
 		in, out := &in.ExternalManagedControlPlane, &out.ExternalManagedControlPlane
 		*out = new(bool)
@@ -114,6 +204,240 @@ func (in *KubernikusControlPlaneStatus) DeepCopy() *KubernikusControlPlaneStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusControlPlaneTemplate) DeepCopyInto(out *KubernikusControlPlaneTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusControlPlaneTemplate.
+func (in *KubernikusControlPlaneTemplate) DeepCopy() *KubernikusControlPlaneTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusControlPlaneTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubernikusControlPlaneTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusControlPlaneTemplateList) DeepCopyInto(out *KubernikusControlPlaneTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubernikusControlPlaneTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusControlPlaneTemplateList.
+func (in *KubernikusControlPlaneTemplateList) DeepCopy() *KubernikusControlPlaneTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusControlPlaneTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubernikusControlPlaneTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusControlPlaneTemplateResource) DeepCopyInto(out *KubernikusControlPlaneTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusControlPlaneTemplateResource.
+func (in *KubernikusControlPlaneTemplateResource) DeepCopy() *KubernikusControlPlaneTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusControlPlaneTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusControlPlaneTemplateResourceSpec) DeepCopyInto(out *KubernikusControlPlaneTemplateResourceSpec) {
+	*out = *in
+	if in.CredentialsRef != nil {
+		in, out := &in.CredentialsRef, &out.CredentialsRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(KubernikusIdentityReference)
+		**out = **in
+	}
+	if in.CustomCNI != nil {
+		in, out := &in.CustomCNI, &out.CustomCNI
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SeedKubeadm != nil {
+		in, out := &in.SeedKubeadm, &out.SeedKubeadm
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Oidc != nil {
+		in, out := &in.Oidc, &out.Oidc
+		*out = new(OIDC)
+		**out = **in
+	}
+	if in.Dex != nil {
+		in, out := &in.Dex, &out.Dex
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Dashboard != nil {
+		in, out := &in.Dashboard, &out.Dashboard
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusControlPlaneTemplateResourceSpec.
+func (in *KubernikusControlPlaneTemplateResourceSpec) DeepCopy() *KubernikusControlPlaneTemplateResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusControlPlaneTemplateResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusControlPlaneTemplateSpec) DeepCopyInto(out *KubernikusControlPlaneTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusControlPlaneTemplateSpec.
+func (in *KubernikusControlPlaneTemplateSpec) DeepCopy() *KubernikusControlPlaneTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusControlPlaneTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusIdentity) DeepCopyInto(out *KubernikusIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusIdentity.
+func (in *KubernikusIdentity) DeepCopy() *KubernikusIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubernikusIdentity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusIdentityList) DeepCopyInto(out *KubernikusIdentityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubernikusIdentity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusIdentityList.
+func (in *KubernikusIdentityList) DeepCopy() *KubernikusIdentityList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusIdentityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubernikusIdentityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusIdentityReference) DeepCopyInto(out *KubernikusIdentityReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusIdentityReference.
+func (in *KubernikusIdentityReference) DeepCopy() *KubernikusIdentityReference {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusIdentityReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernikusIdentitySpec) DeepCopyInto(out *KubernikusIdentitySpec) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(AllowedNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernikusIdentitySpec.
+func (in *KubernikusIdentitySpec) DeepCopy() *KubernikusIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernikusIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OIDC) DeepCopyInto(out *OIDC) {
 	*out = *in
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// fake-kubernikus serves the in-memory kubernikus api of the fakekubernikus package,
+// for local development of the control plane provider without a kubernikus installation.
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/fakekubernikus"
+)
+
+var setupLog = ctrl.Log.WithName("setup")
+
+func main() {
+	var bindAddr string
+	var certFile, keyFile, caOutputFile string
+	var hostnames, tokens string
+	var username, password string
+	var fakeOpts fakekubernikus.Options
+	flag.StringVar(&bindAddr, "bind-address", ":8443", "The address the fake kubernikus api binds to.")
+	flag.StringVar(&certFile, "tls-cert-file", "", "The serving certificate, a self-signed one is generated if empty.")
+	flag.StringVar(&keyFile, "tls-key-file", "", "The key of the serving certificate.")
+	flag.StringVar(&hostnames, "hostnames", "localhost,127.0.0.1",
+		"A comma separated list of the names of the generated serving certificate.")
+	flag.StringVar(&caOutputFile, "ca-output-file", "",
+		"Where to write the generated serving certificate, for use as ca.crt in the credentials secret.")
+	flag.StringVar(&tokens, "tokens", fakekubernikus.DefaultToken, "A comma separated list of the accepted tokens.")
+	flag.StringVar(&username, "username", fakekubernikus.DefaultUsername, "The username accepted by /auth/login.")
+	flag.StringVar(&password, "password", fakekubernikus.DefaultPassword, "The password accepted by /auth/login.")
+	flag.DurationVar(&fakeOpts.PhaseDuration, "phase-duration", 30*time.Second,
+		"How long klusters stay in the Pending, Creating, Upgrading and Terminating phases.")
+	flag.DurationVar(&fakeOpts.CertificateValidity, "certificate-validity", 24*time.Hour,
+		"The validity of the client certificates in the kubeconfigs.")
+	flag.StringVar(&fakeOpts.Domain, "domain", fakekubernikus.DefaultDomain, "The domain of the kluster apiservers.")
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	fakeOpts.Tokens = strings.Split(tokens, ",")
+	fakeOpts.Users = map[string]string{username: password}
+
+	var cert tls.Certificate
+	var err error
+	if certFile != "" {
+		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load serving certificate")
+			os.Exit(1)
+		}
+	} else {
+		var certPEM []byte
+		cert, certPEM, err = fakekubernikus.ServingCertificate(strings.Split(hostnames, ","), time.Now())
+		if err != nil {
+			setupLog.Error(err, "unable to generate serving certificate")
+			os.Exit(1)
+		}
+		if caOutputFile != "" {
+			if err := os.WriteFile(caOutputFile, certPEM, 0o644); err != nil {
+				setupLog.Error(err, "unable to write serving certificate", "file", caOutputFile)
+				os.Exit(1)
+			}
+		}
+	}
+
+	server := &http.Server{
+		Addr:      bindAddr,
+		Handler:   fakekubernikus.New(fakeOpts),
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+	}
+	setupLog.Info("serving fake kubernikus api", "address", bindAddr)
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		setupLog.Error(err, "problem running fake kubernikus api")
+		os.Exit(1)
+	}
+}
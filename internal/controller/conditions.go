@@ -0,0 +1,262 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+
+	"github.com/sapcc/kubernikus/pkg/api/models"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+// readySummaryConditions are the conditions which have to be true for the control plane to be ready.
+var readySummaryConditions = []string{
+	controlplanev1alpha1.CredentialsValidCondition,
+	controlplanev1alpha1.KlusterProvisionedCondition,
+	controlplanev1alpha1.ControlPlaneReadyCondition,
+	controlplanev1alpha1.KubeconfigAvailableCondition,
+	controlplanev1alpha1.CertificatesAvailableCondition,
+}
+
+// setPausedCondition sets the Paused condition and reports whether reconciliation is paused,
+// either by spec.paused of the cluster or by the paused annotation of the control plane.
+func setPausedCondition(kcp *controlplanev1alpha1.KubernikusControlPlane, cluster *capiv1beta1.Cluster) bool {
+	var messages []string
+	if cluster.Spec.Paused {
+		messages = append(messages, "Cluster spec.paused is set to true")
+	}
+	if annotations.HasPaused(kcp) {
+		messages = append(messages, "KubernikusControlPlane has the cluster.x-k8s.io/paused annotation")
+	}
+	if len(messages) == 0 {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.PausedCondition,
+			Status: metav1.ConditionFalse,
+			Reason: controlplanev1alpha1.NotPausedReason,
+		})
+		return false
+	}
+	meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+		Type:    controlplanev1alpha1.PausedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  controlplanev1alpha1.PausedReason,
+		Message: strings.Join(messages, ", "),
+	})
+	return true
+}
+
+// setCredentialsCondition derives the CredentialsValid condition from the result of a kubernikus call.
+// Errors unrelated to authentication leave the condition untouched.
+func setCredentialsCondition(kcp *controlplanev1alpha1.KubernikusControlPlane, err error) {
+	switch {
+	case err == nil:
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.CredentialsValidCondition,
+			Status: metav1.ConditionTrue,
+			Reason: controlplanev1alpha1.CredentialsValidReason,
+		})
+	case kubernikus.IsAuthenticationError(err):
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.CredentialsValidCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.AuthenticationFailedReason,
+			Message: err.Error(),
+		})
+	}
+}
+
+// setKlusterConditions derives the KlusterProvisioned, ControlPlaneReady and UpgradeInProgress
+// conditions from the kluster status copied into the control plane.
+func setKlusterConditions(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+	phase := models.KlusterPhase(kcp.Status.Phase)
+
+	switch phase {
+	case "", models.KlusterPhasePending, models.KlusterPhaseCreating:
+		msg := fmt.Sprintf("Waiting for kubernikus to create kluster %s", kcp.Name)
+		if phase != "" {
+			msg = fmt.Sprintf("Kluster is in phase %s", phase)
+		}
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.KlusterProvisionedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.KlusterProvisioningReason,
+			Message: msg,
+		})
+	default:
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.KlusterProvisionedCondition,
+			Status: metav1.ConditionTrue,
+			Reason: controlplanev1alpha1.KlusterProvisionedReason,
+		})
+	}
+
+	if phase == models.KlusterPhaseRunning {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.ControlPlaneReadyCondition,
+			Status: metav1.ConditionTrue,
+			Reason: controlplanev1alpha1.KlusterRunningReason,
+		})
+	} else {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.ControlPlaneReadyCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.KlusterNotRunningReason,
+			Message: fmt.Sprintf("Kluster is in phase %s", phaseOrUnknown(phase)),
+		})
+	}
+
+	desired := "v" + strings.TrimPrefix(kcp.Spec.Version, "v")
+	switch {
+	case phase == models.KlusterPhaseUpgrading,
+		phase == models.KlusterPhaseRunning && kcp.Spec.Version != "" && kcp.Status.Version != desired:
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.UpgradeInProgressCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  controlplanev1alpha1.UpgradingReason,
+			Message: fmt.Sprintf("Upgrading kluster from %s to %s", kcp.Status.Version, desired),
+		})
+	default:
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.UpgradeInProgressCondition,
+			Status: metav1.ConditionFalse,
+			Reason: controlplanev1alpha1.UpToDateReason,
+		})
+	}
+
+	setContractConditions(kcp)
+}
+
+// setKlusterWarning appends the latest warning event of the kluster to the message
+// of the ControlPlaneReady condition, if the control plane is not ready.
+func setKlusterWarning(kcp *controlplanev1alpha1.KubernikusControlPlane, events []*models.Event) {
+	var latest *models.Event
+	for _, e := range events {
+		// the timestamps are RFC 3339 and sort lexically
+		if e != nil && e.Type == "Warning" && (latest == nil || e.LastTimestamp > latest.LastTimestamp) {
+			latest = e
+		}
+	}
+	c := meta.FindStatusCondition(kcp.Status.Conditions, controlplanev1alpha1.ControlPlaneReadyCondition)
+	if latest == nil || c == nil || c.Status == metav1.ConditionTrue {
+		return
+	}
+	meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+		Type:    c.Type,
+		Status:  c.Status,
+		Reason:  c.Reason,
+		Message: fmt.Sprintf("%s, last warning: %s: %s", c.Message, latest.Reason, latest.Message),
+	})
+}
+
+// setContractConditions sets status.initialized, status.initialization and the Available, Initialized and RollingOut
+// conditions Cluster API expects from control plane providers implementing the v1beta1 and v1beta2 contracts.
+func setContractConditions(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+	phase := models.KlusterPhase(kcp.Status.Phase)
+	serving := phase == models.KlusterPhaseRunning || phase == models.KlusterPhaseUpgrading
+
+	// both contracts start bootstrapping once the control plane is initialized, so the fields agree and are never reset
+	if serving {
+		kcp.Status.Initialized = true
+		kcp.Status.Initialization = &controlplanev1alpha1.KubernikusControlPlaneInitializationStatus{
+			ControlPlaneInitialized: ptr.To(true),
+		}
+	}
+	if kcp.Status.Initialization != nil && ptr.Deref(kcp.Status.Initialization.ControlPlaneInitialized, false) {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.InitializedCondition,
+			Status: metav1.ConditionTrue,
+			Reason: controlplanev1alpha1.InitializedReason,
+		})
+	} else {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.InitializedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.NotInitializedReason,
+			Message: fmt.Sprintf("Kluster is in phase %s", phaseOrUnknown(phase)),
+		})
+	}
+
+	if serving {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.AvailableCondition,
+			Status: metav1.ConditionTrue,
+			Reason: controlplanev1alpha1.AvailableReason,
+		})
+	} else {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.AvailableCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.NotAvailableReason,
+			Message: fmt.Sprintf("Kluster is in phase %s", phaseOrUnknown(phase)),
+		})
+	}
+
+	if upgrade := meta.FindStatusCondition(kcp.Status.Conditions, controlplanev1alpha1.UpgradeInProgressCondition); upgrade != nil && upgrade.Status == metav1.ConditionTrue {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.RollingOutCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  controlplanev1alpha1.RollingOutReason,
+			Message: upgrade.Message,
+		})
+	} else {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.RollingOutCondition,
+			Status: metav1.ConditionFalse,
+			Reason: controlplanev1alpha1.NotRollingOutReason,
+		})
+	}
+}
+
+// setReadyCondition summarizes the readySummaryConditions into the Ready condition.
+// The message lists every condition which is not true.
+func setReadyCondition(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+	var notReady []string
+	for _, conditionType := range readySummaryConditions {
+		c := meta.FindStatusCondition(kcp.Status.Conditions, conditionType)
+		switch {
+		case c == nil:
+			notReady = append(notReady, conditionType+": not yet reported")
+		case c.Status != metav1.ConditionTrue:
+			notReady = append(notReady, fmt.Sprintf("%s: %s", conditionType, conditionMessage(c)))
+		}
+	}
+	if len(notReady) > 0 {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.ReadyCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.NotReadyReason,
+			Message: strings.Join(notReady, "; "),
+		})
+		return
+	}
+	meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+		Type:   controlplanev1alpha1.ReadyCondition,
+		Status: metav1.ConditionTrue,
+		Reason: controlplanev1alpha1.ReadyReason,
+	})
+}
+
+func conditionMessage(c *metav1.Condition) string {
+	if c.Message != "" {
+		return c.Message
+	}
+	return c.Reason
+}
+
+func phaseOrUnknown(phase models.KlusterPhase) string {
+	if phase == "" {
+		return "Unknown"
+	}
+	return string(phase)
+}
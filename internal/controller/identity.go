@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubernikusidentities,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// identityError is returned when a KubernikusIdentity can not be used by a control plane.
+// Reason is used for the CredentialsValid condition.
+type identityError struct {
+	reason string
+	msg    string
+}
+
+func (e *identityError) Error() string {
+	return e.msg
+}
+
+// identitySecretKey returns the key of the credentials secret of the KubernikusIdentity referenced by kcp,
+// after making sure the namespace of kcp is allowed to use the identity.
+func (r *KubernikusControlPlaneReconciler) identitySecretKey(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane) (client.ObjectKey, error) {
+	name := kcp.Spec.IdentityRef.Name
+	if r.IdentityNamespace == "" {
+		return client.ObjectKey{}, errors.New("the namespace holding the secrets of identities is not configured")
+	}
+
+	var identity controlplanev1alpha1.KubernikusIdentity
+	err := r.Get(ctx, client.ObjectKey{Name: name}, &identity)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return client.ObjectKey{}, &identityError{
+				reason: controlplanev1alpha1.IdentityNotFoundReason,
+				msg:    fmt.Sprintf("KubernikusIdentity %s not found", name),
+			}
+		}
+		return client.ObjectKey{}, err
+	}
+
+	allowed, err := r.namespaceAllowed(ctx, identity.Spec.AllowedNamespaces, kcp.Namespace)
+	if err != nil {
+		return client.ObjectKey{}, err
+	}
+	if !allowed {
+		return client.ObjectKey{}, &identityError{
+			reason: controlplanev1alpha1.NamespaceNotAllowedReason,
+			msg:    fmt.Sprintf("KubernikusIdentity %s does not allow namespace %s", name, kcp.Namespace),
+		}
+	}
+	return client.ObjectKey{Namespace: r.IdentityNamespace, Name: identity.Spec.SecretRef}, nil
+}
+
+// namespaceAllowed reports whether namespace is selected by allowed.
+// A nil selection allows no namespace, an empty one allows all.
+func (r *KubernikusControlPlaneReconciler) namespaceAllowed(ctx context.Context, allowed *controlplanev1alpha1.AllowedNamespaces, namespace string) (bool, error) {
+	if allowed == nil {
+		return false, nil
+	}
+	if len(allowed.NamespaceList) == 0 && allowed.Selector == nil {
+		return true, nil
+	}
+	if slices.Contains(allowed.NamespaceList, namespace) {
+		return true, nil
+	}
+	if allowed.Selector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(allowed.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	var ns v1.Namespace
+	err = r.Get(ctx, client.ObjectKey{Name: namespace}, &ns)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+func TestIdentitySecretKey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = controlplanev1alpha1.AddToScheme(scheme)
+
+	identity := func(name string, allowed *controlplanev1alpha1.AllowedNamespaces) *controlplanev1alpha1.KubernikusIdentity {
+		return &controlplanev1alpha1.KubernikusIdentity{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       controlplanev1alpha1.KubernikusIdentitySpec{SecretRef: "kubernikus-credentials", AllowedNamespaces: allowed},
+		}
+	}
+	r := &KubernikusControlPlaneReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+			identity("none", nil),
+			identity("all", &controlplanev1alpha1.AllowedNamespaces{}),
+			identity("list", &controlplanev1alpha1.AllowedNamespaces{NamespaceList: []string{"team-b"}}),
+			identity("selector", &controlplanev1alpha1.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			}),
+		).Build(),
+		IdentityNamespace: "kubernikus-system",
+	}
+
+	tests := []struct {
+		identity  string
+		namespace string
+		reason    string
+	}{
+		{identity: "none", namespace: "team-a", reason: controlplanev1alpha1.NamespaceNotAllowedReason},
+		{identity: "all", namespace: "team-a"},
+		{identity: "list", namespace: "team-b"},
+		{identity: "list", namespace: "team-a", reason: controlplanev1alpha1.NamespaceNotAllowedReason},
+		{identity: "selector", namespace: "team-a"},
+		{identity: "selector", namespace: "team-b", reason: controlplanev1alpha1.NamespaceNotAllowedReason},
+		{identity: "missing", namespace: "team-a", reason: controlplanev1alpha1.IdentityNotFoundReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.identity+"/"+tt.namespace, func(t *testing.T) {
+			kcp := &controlplanev1alpha1.KubernikusControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace, Name: "kluster"},
+				Spec: controlplanev1alpha1.KubernikusControlPlaneSpec{
+					IdentityRef: &controlplanev1alpha1.KubernikusIdentityReference{Name: tt.identity},
+				},
+			}
+			key, err := r.identitySecretKey(context.Background(), kcp)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if key.Namespace != "kubernikus-system" || key.Name != "kubernikus-credentials" {
+					t.Errorf("unexpected secret %s", key)
+				}
+				return
+			}
+			var identityErr *identityError
+			if !errors.As(err, &identityErr) {
+				t.Fatalf("expected identity error with reason %s, got %v", tt.reason, err)
+			}
+			if identityErr.reason != tt.reason {
+				t.Errorf("expected reason %s, got %s", tt.reason, identityErr.reason)
+			}
+		})
+	}
+}
+
+func TestGetCredentialsTokenFile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = controlplanev1alpha1.AddToScheme(scheme)
+
+	tokenFile := func(namespace, name, path string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data: map[string][]byte{
+				kubernikus.HostKey:      []byte("kubernikus.example.com"),
+				kubernikus.AuthModeKey:  []byte(kubernikus.AuthModeTokenFile),
+				kubernikus.TokenFileKey: []byte(path),
+			},
+		}
+	}
+	r := &KubernikusControlPlaneReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			tokenFile("team-a", "tenant", "/var/run/secrets/kubernetes.io/serviceaccount/token"),
+			tokenFile("kubernikus-system", "allowed", "/var/run/secrets/kubernikus/token"),
+			tokenFile("kubernikus-system", "service-account", "/var/run/secrets/kubernetes.io/serviceaccount/token"),
+			&controlplanev1alpha1.KubernikusIdentity{
+				ObjectMeta: metav1.ObjectMeta{Name: "allowed"},
+				Spec:       controlplanev1alpha1.KubernikusIdentitySpec{SecretRef: "allowed", AllowedNamespaces: &controlplanev1alpha1.AllowedNamespaces{}},
+			},
+			&controlplanev1alpha1.KubernikusIdentity{
+				ObjectMeta: metav1.ObjectMeta{Name: "service-account"},
+				Spec:       controlplanev1alpha1.KubernikusIdentitySpec{SecretRef: "service-account", AllowedNamespaces: &controlplanev1alpha1.AllowedNamespaces{}},
+			},
+		).Build(),
+		IdentityNamespace: "kubernikus-system",
+		TokenFileDirs:     []string{"/var/run/secrets/kubernikus"},
+	}
+	cluster := &capiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "tenant"}}
+
+	for name, tc := range map[string]struct {
+		spec     controlplanev1alpha1.KubernikusControlPlaneSpec
+		rejected bool
+	}{
+		"namespaced secret": {
+			spec:     controlplanev1alpha1.KubernikusControlPlaneSpec{},
+			rejected: true,
+		},
+		"credentialsRef": {
+			spec:     controlplanev1alpha1.KubernikusControlPlaneSpec{CredentialsRef: &v1.LocalObjectReference{Name: "tenant"}},
+			rejected: true,
+		},
+		"identity": {
+			spec: controlplanev1alpha1.KubernikusControlPlaneSpec{IdentityRef: &controlplanev1alpha1.KubernikusIdentityReference{Name: "allowed"}},
+		},
+		"identity outside of the allowed directories": {
+			spec:     controlplanev1alpha1.KubernikusControlPlaneSpec{IdentityRef: &controlplanev1alpha1.KubernikusIdentityReference{Name: "service-account"}},
+			rejected: true,
+		},
+	} {
+		kcp := &controlplanev1alpha1.KubernikusControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "tenant"},
+			Spec:       tc.spec,
+		}
+		_, err := r.getCredentials(context.Background(), kcp, cluster)
+		if !tc.rejected {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+			continue
+		}
+		var credsErr *credentialsError
+		if !errors.As(err, &credsErr) {
+			t.Errorf("%s: expected credentials error, got %v", name, err)
+		}
+		if !meta.IsStatusConditionFalse(kcp.Status.Conditions, controlplanev1alpha1.CredentialsValidCondition) {
+			t.Errorf("%s: expected CredentialsValid to be false", name)
+		}
+	}
+}
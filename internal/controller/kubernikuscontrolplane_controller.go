@@ -6,21 +6,37 @@ package controller
 import (
 	"context"
 	b64 "encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/sapcc/kubernikus/pkg/api/models"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
 	certs2 "sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
 
@@ -30,30 +46,43 @@ import (
 // KubernikusControlPlaneReconciler reconciles a KubernikusControlPlane object
 type KubernikusControlPlaneReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-}
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 
-var periodicReconciliationResult = ctrl.Result{RequeueAfter: 10 * time.Minute}
+	// IdentityNamespace is the namespace holding the credentials secrets of KubernikusIdentities.
+	IdentityNamespace string
+	// Clients shares the kubernikus clients between reconciles.
+	Clients *kubernikus.ClientPool
+	// NewKubernikusAPI returns the kubernikus api for the credentials of a control plane.
+	// It defaults to the clients of Clients, tests inject fakes.
+	NewKubernikusAPI kubernikus.APIFactory
+	// RequeueIntervals configures when control planes are reconciled again, by the phase of their kluster.
+	RequeueIntervals RequeueIntervals
+	// TokenFileDirs are the directories the token-file mode of KubernikusIdentities may read tokens from.
+	// The mode is rejected for credentials in the namespaces of control planes.
+	TokenFileDirs []string
+	// WatchFilterValue restricts reconciliation to objects with the cluster.x-k8s.io/watch-filter label of this value.
+	WatchFilterValue string
+}
 
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubernikuscontrolplanes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubernikuscontrolplanes/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubernikuscontrolplanes/finalizers,verbs=update
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch;update;patch
-//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update
-
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the KubernikusControlPlane object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile creates the kluster of a KubernikusControlPlane in kubernikus and keeps it in line with the spec.
+// Once the kluster is running its endpoint is propagated to the owner cluster, and the kubeconfig, CA and
+// service account secrets of the cluster are maintained. The conditions in the status report the progress.
 //
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
-func (r *KubernikusControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+// On deletion the kluster is handled according to the deletion policy before the finalizer is released,
+// also when the owner cluster is already gone.
+func (r *KubernikusControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	logger := log.FromContext(ctx).WithValues("kubernikuscontrolplane", req.NamespacedName)
 
 	logger.Info("Reconciling KubernikusControlPlane")
+	intervals := r.RequeueIntervals.withDefaults()
 
 	var kcp controlplanev1alpha1.KubernikusControlPlane
 
@@ -61,25 +90,32 @@ func (r *KubernikusControlPlaneReconciler) Reconcile(ctx context.Context, req ct
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Info("KubernikusControlPlane may be deleted")
-			return periodicReconciliationResult, nil
+			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "Failed to get KubernikusControlPlane")
 		return ctrl.Result{}, err
 	}
 
 	if len(kcp.GetOwnerReferences()) == 0 {
+		if !kcp.DeletionTimestamp.IsZero() {
+			logger.Info("KubernikusControlPlane has no owner reference, releasing it without terminating the kluster")
+			return ctrl.Result{}, r.removeFinalizer(ctx, &kcp)
+		}
+		// adding the owner reference triggers the next reconcile
 		logger.Info("KubernikusControlPlane has no owner reference, skipping")
-		return ctrl.Result{Requeue: true}, nil
+		return ctrl.Result{}, nil
 	}
 
-	// TODO: add finalizer
-
 	cluster, err := util.GetOwnerCluster(ctx, r.Client, kcp.ObjectMeta)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			logger.Error(err, "Failed to get owner cluster, stopping reconciliation")
-			return periodicReconciliationResult, nil
-		}
+	switch {
+	case errors.IsNotFound(err) && !kcp.DeletionTimestamp.IsZero():
+		// the cluster can be removed before its control plane, the kluster still has to be handled
+		logger.Info("Owner cluster is gone, deleting KubernikusControlPlane without it")
+		cluster = ownerClusterStub(&kcp)
+	case errors.IsNotFound(err):
+		logger.Error(err, "Failed to get owner cluster, stopping reconciliation")
+		return ctrl.Result{RequeueAfter: intervals.Running}, nil
+	case err != nil:
 		logger.Error(err, "Failed to get owner cluster")
 		return ctrl.Result{}, err
 	}
@@ -89,38 +125,159 @@ func (r *KubernikusControlPlaneReconciler) Reconcile(ctx context.Context, req ct
 	// check owner cluster
 	// cluster.Status.InfrastructureReady
 
-	var sec v1.Secret
-	err = r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, &sec)
+	original := kcp.DeepCopy()
+	defer func() {
+		if kcp.DeletionTimestamp.IsZero() {
+			setReadyCondition(&kcp)
+		}
+		if equality.Semantic.DeepEqual(original.Status, kcp.Status) {
+			return
+		}
+		if err := r.Status().Update(ctx, &kcp); client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to update status")
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// the watches on the cluster and the control plane trigger the reconcile once they are unpaused
+	if setPausedCondition(&kcp, cluster) {
+		logger.Info("Reconciliation is paused")
+		return ctrl.Result{}, nil
+	}
+
+	// orphaned klusters are not touched, missing credentials must not block the deletion
+	if !kcp.DeletionTimestamp.IsZero() && deletionPolicy(&kcp) == controlplanev1alpha1.DeletionPolicyOrphan {
+		return r.reconcileDelete(ctx, &kcp, cluster, nil, intervals)
+	}
+
+	creds, err := r.getCredentials(ctx, &kcp, cluster)
+	if err != nil {
+		logger.Error(err, "Failed to get credentials")
+		var credsErr *credentialsError
+		if !kcp.DeletionTimestamp.IsZero() && stderrors.As(err, &credsErr) {
+			// retrying does not help, the watches on the credentials trigger the next attempt
+			r.blockDeletion(&kcp, err)
+			return ctrl.Result{RequeueAfter: intervals.Running}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	logger.Info("Got credentials", "host", creds.Host, "mode", creds.AuthMode)
+
+	kks, err := r.NewKubernikusAPI(creds)
 	if err != nil {
-		logger.Error(err, "Failed to get secret")
+		logger.Error(err, "Failed to get kubernikus client")
 		return ctrl.Result{}, err
 	}
-	conv := convertSecret(&sec)
-	logger.Info("Got secret", "host", conv["host"], "user", conv["user"], "conn", conv["conn"])
 
-	kks := kubernikus.NewClient(conv["host"], conv["user"], conv["pass"], conv["conn"], conv["auth"]+"/auth/login")
-	err = kks.EnsureControlPlane(&kcp, logger)
+	if !kcp.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &kcp, cluster, kks, intervals)
+	}
+
+	if controllerutil.AddFinalizer(&kcp, controlplanev1alpha1.KubernikusControlPlaneFinalizer) {
+		// the update returns the stored status, keep the conditions set so far
+		status := kcp.Status.DeepCopy()
+		err = r.Update(ctx, &kcp)
+		if err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+		kcp.Status = *status
+	}
+
+	ensured, err := kks.EnsureControlPlane(ctx, &kcp, logger)
+	setCredentialsCondition(&kcp, err)
 	if err != nil {
 		logger.Error(err, "Failed to ensure control plane")
+		if kubernikus.IsAuthenticationError(err) {
+			kks.InvalidateToken()
+		} else {
+			meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+				Type:    controlplanev1alpha1.KlusterProvisionedCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  controlplanev1alpha1.KlusterProvisioningFailedReason,
+				Message: err.Error(),
+			})
+		}
+		if failure := kubernikus.AsTerminal(err); failure != nil {
+			r.setFailure(&kcp, failure)
+			return ctrl.Result{RequeueAfter: intervals.Running}, nil
+		}
 		return ctrl.Result{}, err
 	}
+	kcp.Status.FailureReason = ""
+	kcp.Status.FailureMessage = ""
+	if ensured.Created {
+		r.Recorder.Eventf(&kcp, v1.EventTypeNormal, "KlusterCreated", "Created kluster %s", kcp.Name)
+	}
+	if len(ensured.UpdatedFields) > 0 {
+		r.Recorder.Eventf(&kcp, v1.EventTypeNormal, "KlusterUpdated",
+			"Updated kluster fields: %s", strings.Join(ensured.UpdatedFields, ", "))
+		now := metav1.Now()
+		kcp.Status.UpdatedFields = ensured.UpdatedFields
+		kcp.Status.LastUpdateTime = &now
+	}
+	if len(ensured.DriftedFields) > 0 {
+		msg := strings.Join(ensured.DriftedFields, "; ")
+		if !meta.IsStatusConditionFalse(kcp.Status.Conditions, controlplanev1alpha1.ImmutableFieldsInSyncCondition) {
+			r.Recorder.Eventf(&kcp, v1.EventTypeWarning, controlplanev1alpha1.ImmutableFieldsDriftedReason,
+				"Kluster differs from spec in immutable fields: %s", msg)
+		}
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.ImmutableFieldsInSyncCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.ImmutableFieldsDriftedReason,
+			Message: msg,
+		})
+	} else {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:   controlplanev1alpha1.ImmutableFieldsInSyncCondition,
+			Status: metav1.ConditionTrue,
+			Reason: controlplanev1alpha1.ImmutableFieldsInSyncReason,
+		})
+	}
 
 	// get the latest status from kubernikus
-	status, err := kks.GetKKSStatus(&kcp, logger)
+	status, err := kks.GetKKSStatus(ctx, &kcp, logger)
 	if err != nil {
 		logger.Error(err, "Failed to get status")
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.ControlPlaneReadyCondition,
+			Status:  metav1.ConditionUnknown,
+			Reason:  controlplanev1alpha1.KlusterStatusUnknownReason,
+			Message: err.Error(),
+		})
 		return ctrl.Result{}, err
 	}
 	// update the status of the kcp
-	kcp.Status = *status
-	err = r.Status().Update(ctx, &kcp)
-	if err != nil {
-		logger.Error(err, "Failed to update status")
-		return ctrl.Result{}, err
+	existed := kcp.Status.Phase != "" && !ensured.Created
+	kcp.Status.Ready = status.Ready
+	kcp.Status.Version = status.Version
+	kcp.Status.Phase = status.Phase
+	setKlusterConditions(&kcp)
+	if status.Phase == "" && existed {
+		// the kluster vanished since the last reconcile, the next one creates it again
+		msg := fmt.Sprintf("Kluster %s is missing in kubernikus", kcp.Name)
+		r.Recorder.Event(&kcp, v1.EventTypeWarning, controlplanev1alpha1.KlusterNotFoundReason, msg)
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.KlusterProvisionedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.KlusterNotFoundReason,
+			Message: msg,
+		})
+	}
+	if !status.Ready && status.Phase != "" {
+		// surface why kubernikus is stuck, the events are only informational
+		events, err := kks.GetKKSEvents(ctx, &kcp)
+		if err != nil {
+			logger.Error(err, "Failed to get kluster events")
+		} else {
+			setKlusterWarning(&kcp, events)
+		}
 	}
+
 	// set owner cp endpoint if status is ready
 	if status.Ready && cluster.Spec.ControlPlaneEndpoint.Host == "" {
-		ep, err := kks.GetKKSEndpoint(&kcp)
+		ep, err := kks.GetKKSEndpoint(ctx, &kcp)
 		if err != nil {
 			logger.Error(err, "Failed to get endpoint")
 			return ctrl.Result{}, err
@@ -133,127 +290,555 @@ func (r *KubernikusControlPlaneReconciler) Reconcile(ctx context.Context, req ct
 		}
 	}
 	// set necessary secrets and labels according to status
-	if status.Ready {
-		// check if secret is already present
-		kcSecret, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.Kubeconfig)
+	if !status.Ready {
+		for _, conditionType := range []string{
+			controlplanev1alpha1.KubeconfigAvailableCondition,
+			controlplanev1alpha1.CertificatesAvailableCondition,
+		} {
+			if !meta.IsStatusConditionTrue(kcp.Status.Conditions, conditionType) {
+				meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+					Type:    conditionType,
+					Status:  metav1.ConditionFalse,
+					Reason:  controlplanev1alpha1.WaitingForControlPlaneReason,
+					Message: "Waiting for the kluster to be running",
+				})
+			}
+		}
+		requeueAfter := intervals.forPhase(models.KlusterPhase(status.Phase))
+		logger.Info("cluster not ready yet", "phase", status.Phase, "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	kcSecret, err := r.reconcileKubeconfig(ctx, &kcp, cluster, kks, intervals.KubeconfigRotation)
+	if err != nil {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.KubeconfigAvailableCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.KubeconfigFailedReason,
+			Message: err.Error(),
+		})
+		return ctrl.Result{}, err
+	}
+	meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+		Type:   controlplanev1alpha1.KubeconfigAvailableCondition,
+		Status: metav1.ConditionTrue,
+		Reason: controlplanev1alpha1.KubeconfigAvailableReason,
+	})
+
+	err = r.reconcileCertificates(ctx, &kcp, cluster, kks, kcSecret.Data[secret.KubeconfigDataName])
+	if err != nil {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.CertificatesAvailableCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.CertificatesFailedReason,
+			Message: err.Error(),
+		})
+		return ctrl.Result{}, err
+	}
+	meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+		Type:   controlplanev1alpha1.CertificatesAvailableCondition,
+		Status: metav1.ConditionTrue,
+		Reason: controlplanev1alpha1.CertificatesAvailableReason,
+	})
+
+	err = r.adoptSecrets(ctx, &kcp, cluster)
+	if err != nil {
+		logger.Error(err, "Failed to adopt secrets")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: intervals.untilKubeconfigRotation(kcSecret, time.Now())}, nil
+}
+
+// reconcileKubeconfig creates the kubeconfig secret of the owner cluster and rotates it
+// once the client certificate expires within rotateBefore.
+func (r *KubernikusControlPlaneReconciler) reconcileKubeconfig(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane, cluster *capiv1beta1.Cluster, kks kubernikus.KubernikusAPI, rotateBefore time.Duration) (*v1.Secret, error) {
+	logger := log.FromContext(ctx).WithValues("kubernikuscontrolplane", client.ObjectKeyFromObject(kcp))
+
+	// check if secret is already present
+	kcSecret, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.Kubeconfig)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to get kubeconfig secret")
+			return nil, err
+		}
+		// if not create it
+		logger.Info("Kubeconfig secret not found, creating")
+		kcStr, err := kks.GetKKSKubeconfig(ctx, kcp, logger)
 		if err != nil {
-			if errors.IsNotFound(err) {
-				// if not create it
-				logger.Info("Kubeconfig secret not found, creating")
-				kcStr, err := kks.GetKKSKubeconfig(&kcp, logger)
-				if err != nil {
-					logger.Error(err, "Failed to get kubeconfig")
-					return ctrl.Result{}, err
-				}
-				logger.Info("generating kubeconfig secret")
-				kcSecret = kubeconfig.GenerateSecret(cluster, []byte(kcStr))
-				err = r.Create(ctx, kcSecret)
-				if err != nil {
-					logger.Error(err, "Failed to create kubeconfig secret")
-					return ctrl.Result{}, err
-				}
-				logger.Info("loading kubeconfig")
-				authInfo, err := clientcmd.Load([]byte(kcStr))
-				if err != nil {
-					logger.Error(err, "Failed to load kubeconfig")
-					return ctrl.Result{}, err
-				}
-				logger.Info("getting ca secret")
-				caSec, err := kks.GetKKSCa(&kcp, logger)
-				if err != nil {
-					logger.Error(err, "Failed to get ca secret")
-					return ctrl.Result{}, err
-				}
-				caKey, err := b64.StdEncoding.DecodeString(caSec.StringData["tls.key"])
-				if err != nil {
-					logger.Error(err, "Failed to decode ca cert key")
-					return ctrl.Result{}, err
-				}
-				logger.Info("context", "current", authInfo.Contexts[authInfo.CurrentContext])
-				aIStr := authInfo.Contexts[authInfo.CurrentContext].AuthInfo
-				cCStr := authInfo.Contexts[authInfo.CurrentContext].Cluster
-				saKeyData := authInfo.AuthInfos[aIStr].ClientKeyData
-				saCertData := authInfo.AuthInfos[aIStr].ClientCertificateData
-				saCert := secret.Certificate{
-					Purpose: secret.ServiceAccount,
-					KeyPair: &certs2.KeyPair{
-						Cert: saCertData,
-						Key:  saKeyData,
-					},
-					External:  true,
-					Generated: true,
-				}
-				caCert := secret.Certificate{
-					Purpose: secret.ClusterCA,
-					KeyPair: &certs2.KeyPair{
-						Key:  caKey,
-						Cert: authInfo.Clusters[cCStr].CertificateAuthorityData,
-					},
-					External:  true,
-					Generated: true,
-				}
-				certs := secret.Certificates{&saCert, &caCert}
-				gvk := controlplanev1alpha1.GroupVersion.WithKind("KubernikusControlPlane")
-				f := false
-				cRef := metav1.OwnerReference{
-					APIVersion:         controlplanev1alpha1.GroupVersion.String(),
-					Kind:               gvk.Kind,
-					Name:               kcp.Name,
-					UID:                kcp.UID,
-					Controller:         &f,
-					BlockOwnerDeletion: &f,
-				}
-				logger.Info("presavegen", "object", util.ObjectKey(cluster), "cRef", cRef)
-				err = certs.SaveGenerated(ctx, r.Client, util.ObjectKey(cluster), cRef)
-				if err != nil {
-					logger.Error(err, "Failed to create secrets")
-					return ctrl.Result{}, err
-				}
-			} else {
-				logger.Error(err, "Failed to get kubeconfig secret")
-				return ctrl.Result{}, err
+			logger.Error(err, "Failed to get kubeconfig")
+			return nil, err
+		}
+		logger.Info("generating kubeconfig secret")
+		kcSecret = kubeconfig.GenerateSecretWithOwner(util.ObjectKey(cluster), []byte(kcStr), controllerRef(kcp))
+		setMoveLabel(kcSecret)
+		setSecretLabel(kcSecret)
+		err = r.Create(ctx, kcSecret)
+		if err != nil {
+			logger.Error(err, "Failed to create kubeconfig secret")
+			return nil, err
+		}
+	}
+	// if yes - check if it needs rotation
+	rotate, err := kubeconfig.NeedsClientCertRotation(kcSecret, rotateBefore)
+	if err != nil {
+		logger.Error(err, "Failed to check kubeconfig for rotation")
+		return nil, err
+	}
+	if rotate {
+		logger.Info("Kubeconfig needs rotation, updating")
+		kcStr, err := kks.GetKKSKubeconfig(ctx, kcp, logger)
+		if err != nil {
+			logger.Error(err, "Failed to get kubeconfig")
+			return nil, err
+		}
+		if kcStr == string(kcSecret.Data[secret.KubeconfigDataName]) {
+			logger.Info("Kubernikus returned the same kubeconfig, keeping it")
+			return kcSecret, nil
+		}
+		kcSecret.Data[secret.KubeconfigDataName] = []byte(kcStr)
+		err = r.Update(ctx, kcSecret)
+		if err != nil {
+			logger.Error(err, "Failed to update kubeconfig secret")
+			return nil, err
+		}
+	}
+	return kcSecret, nil
+}
+
+// reconcileCertificates creates the cluster CA and service account secrets of the owner cluster if they are missing.
+// The service account key pair is the client certificate of the kubeconfig, the CA key is fetched from kubernikus.
+func (r *KubernikusControlPlaneReconciler) reconcileCertificates(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane, cluster *capiv1beta1.Cluster, kks kubernikus.KubernikusAPI, kcData []byte) error {
+	logger := log.FromContext(ctx).WithValues("kubernikuscontrolplane", client.ObjectKeyFromObject(kcp))
+
+	missing := map[secret.Purpose]bool{}
+	for _, purpose := range []secret.Purpose{secret.ServiceAccount, secret.ClusterCA} {
+		_, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), purpose)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to get secret", "purpose", purpose)
+				return err
 			}
+			missing[purpose] = true
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	logger.Info("loading kubeconfig")
+	authInfo, err := clientcmd.Load(kcData)
+	if err != nil {
+		logger.Error(err, "Failed to load kubeconfig")
+		return err
+	}
+	logger.Info("context", "current", authInfo.Contexts[authInfo.CurrentContext])
+	aIStr := authInfo.Contexts[authInfo.CurrentContext].AuthInfo
+	cCStr := authInfo.Contexts[authInfo.CurrentContext].Cluster
+
+	var certs secret.Certificates
+	if missing[secret.ServiceAccount] {
+		certs = append(certs, &secret.Certificate{
+			Purpose: secret.ServiceAccount,
+			KeyPair: &certs2.KeyPair{
+				Cert: authInfo.AuthInfos[aIStr].ClientCertificateData,
+				Key:  authInfo.AuthInfos[aIStr].ClientKeyData,
+			},
+			External:  true,
+			Generated: true,
+		})
+	}
+	if missing[secret.ClusterCA] {
+		logger.Info("getting ca secret")
+		caSec, err := kks.GetKKSCa(ctx, kcp, logger)
+		if err != nil {
+			logger.Error(err, "Failed to get ca secret")
+			return err
+		}
+		caKey, err := b64.StdEncoding.DecodeString(caSec.StringData["tls.key"])
+		if err != nil {
+			logger.Error(err, "Failed to decode ca cert key")
+			return err
 		}
-		// if yes - check if it needs rotation
-		rotate, err := kubeconfig.NeedsClientCertRotation(kcSecret, time.Minute*30)
+		certs = append(certs, &secret.Certificate{
+			Purpose: secret.ClusterCA,
+			KeyPair: &certs2.KeyPair{
+				Key:  caKey,
+				Cert: authInfo.Clusters[cCStr].CertificateAuthorityData,
+			},
+			External:  true,
+			Generated: true,
+		})
+	}
+
+	err = certs.SaveGenerated(ctx, r.Client, util.ObjectKey(cluster), controllerRef(kcp))
+	if err != nil {
+		logger.Error(err, "Failed to create secrets")
+		return err
+	}
+	return nil
+}
+
+// reconcileDelete handles the kluster according to the deletion policy of the control plane.
+// Unless the kluster is orphaned it is terminated in kubernikus and the reconciler waits for it to be gone.
+// Afterwards the secrets created for the owner cluster are removed or retained and the finalizer is released.
+// Status changes are persisted by Reconcile.
+func (r *KubernikusControlPlaneReconciler) reconcileDelete(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane, cluster *capiv1beta1.Cluster, kks kubernikus.KubernikusAPI, intervals RequeueIntervals) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("kubernikuscontrolplane", client.ObjectKeyFromObject(kcp))
+
+	if !controllerutil.ContainsFinalizer(kcp, controlplanev1alpha1.KubernikusControlPlaneFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy := deletionPolicy(kcp)
+	logger = logger.WithValues("deletionPolicy", policy)
+
+	if policy == controlplanev1alpha1.DeletionPolicyOrphan {
+		logger.Info("Orphaning kluster")
+		r.Recorder.Eventf(kcp, v1.EventTypeNormal, "KlusterOrphaned",
+			"Deletion policy %s leaves kluster %s untouched in kubernikus", policy, kcp.Name)
+	} else {
+		gone, err := kks.TerminateControlPlane(ctx, kcp, logger)
+		setCredentialsCondition(kcp, err)
 		if err != nil {
-			logger.Error(err, "Failed to check kubeconfig for rotation")
+			logger.Error(err, "Failed to terminate control plane")
+			r.Recorder.Eventf(kcp, v1.EventTypeWarning, controlplanev1alpha1.DeletionFailedReason,
+				"Failed to terminate kluster %s: %v", kcp.Name, err)
+			meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+				Type:    controlplanev1alpha1.DeletingCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  controlplanev1alpha1.DeletionFailedReason,
+				Message: err.Error(),
+			})
+			if failure := kubernikus.AsTerminal(err); failure != nil {
+				r.setFailure(kcp, failure)
+				return ctrl.Result{RequeueAfter: intervals.Running}, nil
+			}
 			return ctrl.Result{}, err
 		}
-		if rotate {
-			logger.Info("Kubeconfig needs rotation, updating")
-			kcStr, err := kks.GetKKSKubeconfig(&kcp, logger)
-			if err != nil {
-				logger.Error(err, "Failed to get kubeconfig")
-				return ctrl.Result{}, err
+		kcp.Status.FailureReason = ""
+		kcp.Status.FailureMessage = ""
+		if !gone {
+			if !meta.IsStatusConditionTrue(kcp.Status.Conditions, controlplanev1alpha1.DeletingCondition) {
+				r.Recorder.Eventf(kcp, v1.EventTypeNormal, controlplanev1alpha1.KlusterTerminatingReason,
+					"Deletion policy %s terminates kluster %s", policy, kcp.Name)
 			}
-			kcSecret = kubeconfig.GenerateSecret(cluster, []byte(kcStr))
-			err = r.Update(ctx, kcSecret)
+			meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+				Type:    controlplanev1alpha1.DeletingCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  controlplanev1alpha1.KlusterTerminatingReason,
+				Message: "Waiting for kubernikus to terminate the kluster",
+			})
+			kcp.Status.Ready = false
+			meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+				Type:    controlplanev1alpha1.ReadyCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  controlplanev1alpha1.KlusterTerminatingReason,
+				Message: "Kluster is being terminated",
+			})
+			meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+				Type:    controlplanev1alpha1.AvailableCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  controlplanev1alpha1.NotAvailableReason,
+				Message: "Kluster is being terminated",
+			})
+			return ctrl.Result{RequeueAfter: intervals.Terminating}, nil
+		}
+		deleting := meta.FindStatusCondition(kcp.Status.Conditions, controlplanev1alpha1.DeletingCondition)
+		if deleting == nil || deleting.Reason != controlplanev1alpha1.KlusterTerminatingReason {
+			logger.Info("Kluster is missing, nothing to terminate")
+			r.Recorder.Eventf(kcp, v1.EventTypeWarning, controlplanev1alpha1.KlusterNotFoundReason,
+				"Kluster %s is missing in kubernikus, nothing to terminate", kcp.Name)
+		} else {
+			logger.Info("Kluster terminated")
+			r.Recorder.Eventf(kcp, v1.EventTypeNormal, "KlusterTerminated", "Kluster %s has been terminated", kcp.Name)
+		}
+	}
+
+	for _, purpose := range []secret.Purpose{secret.Kubeconfig, secret.ClusterCA, secret.ServiceAccount} {
+		key := client.ObjectKey{Namespace: cluster.Namespace, Name: secret.Name(cluster.Name, purpose)}
+		if policy == controlplanev1alpha1.DeletionPolicyRetain {
+			err := r.retainSecret(ctx, key)
 			if err != nil {
-				logger.Error(err, "Failed to update kubeconfig secret")
+				logger.Error(err, "Failed to retain secret", "secret", key.Name)
 				return ctrl.Result{}, err
 			}
+			continue
+		}
+		err := r.Delete(ctx, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})
+		if client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to delete secret", "secret", key.Name)
+			return ctrl.Result{}, err
 		}
+	}
+	if policy == controlplanev1alpha1.DeletionPolicyRetain {
+		r.Recorder.Eventf(kcp, v1.EventTypeNormal, "SecretsRetained",
+			"Deletion policy %s keeps the secrets of cluster %s", policy, cluster.Name)
 	} else {
-		logger.Info("cluster not ready yet")
-		return ctrl.Result{Requeue: true}, nil
+		r.Recorder.Eventf(kcp, v1.EventTypeNormal, "SecretsDeleted",
+			"Deletion policy %s removed the secrets of cluster %s", policy, cluster.Name)
+	}
+
+	return ctrl.Result{}, r.removeFinalizer(ctx, kcp)
+}
+
+// blockDeletion reports that the kluster can not be terminated as the credentials of the control plane are unusable.
+// The finalizer stays until the credentials are fixed or it is removed by hand.
+func (r *KubernikusControlPlaneReconciler) blockDeletion(kcp *controlplanev1alpha1.KubernikusControlPlane, err error) {
+	msg := fmt.Sprintf("Kluster can not be terminated: %v. Fix the credentials, or remove the finalizer %s "+
+		"to release the KubernikusControlPlane and leave the kluster in kubernikus",
+		err, controlplanev1alpha1.KubernikusControlPlaneFinalizer)
+	deleting := meta.FindStatusCondition(kcp.Status.Conditions, controlplanev1alpha1.DeletingCondition)
+	if deleting == nil || deleting.Reason != controlplanev1alpha1.DeletionBlockedReason || deleting.Message != msg {
+		r.Recorder.Event(kcp, v1.EventTypeWarning, controlplanev1alpha1.DeletionBlockedReason, msg)
+	}
+	meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+		Type:    controlplanev1alpha1.DeletingCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  controlplanev1alpha1.DeletionBlockedReason,
+		Message: msg,
+	})
+}
+
+// deletionPolicy returns the deletion policy of the control plane, defaulting to Delete.
+func deletionPolicy(kcp *controlplanev1alpha1.KubernikusControlPlane) controlplanev1alpha1.DeletionPolicy {
+	if kcp.Spec.DeletionPolicy == "" {
+		return controlplanev1alpha1.DeletionPolicyDelete
+	}
+	return kcp.Spec.DeletionPolicy
+}
+
+// ownerClusterStub stands in for the deleted owner cluster of a control plane.
+// It only carries the name and namespace, which are enough to find the credentials and secrets of the cluster.
+func ownerClusterStub(kcp *controlplanev1alpha1.KubernikusControlPlane) *capiv1beta1.Cluster {
+	return &capiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: kcp.Namespace, Name: ownerClusterName(kcp)}}
+}
+
+// ownerClusterName returns the name of the Cluster owning the control plane, or an empty string.
+func ownerClusterName(kcp *controlplanev1alpha1.KubernikusControlPlane) string {
+	for _, ref := range kcp.OwnerReferences {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && ref.Kind == "Cluster" && gv.Group == capiv1beta1.GroupVersion.Group {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// credentialsError is returned when the credentials of a control plane are missing or invalid.
+// The CredentialsValid condition reports the details.
+type credentialsError struct {
+	err error
+}
+
+func (e *credentialsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *credentialsError) Unwrap() error {
+	return e.err
+}
+
+// credentialsSecretKey returns the key of the secret holding the kubernikus credentials of the control plane.
+// Control planes without a credentialsRef or identityRef use the secret named after their owner cluster.
+func (r *KubernikusControlPlaneReconciler) credentialsSecretKey(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane, cluster *capiv1beta1.Cluster) (client.ObjectKey, error) {
+	switch {
+	case kcp.Spec.IdentityRef != nil:
+		return r.identitySecretKey(ctx, kcp)
+	case kcp.Spec.CredentialsRef != nil && kcp.Spec.CredentialsRef.Name != "":
+		return client.ObjectKey{Namespace: kcp.Namespace, Name: kcp.Spec.CredentialsRef.Name}, nil
+	default:
+		return client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, nil
+	}
+}
+
+// getCredentials reads and validates the kubernikus credentials of the control plane.
+// Problems with the identity or the secret are reported in the CredentialsValid condition.
+func (r *KubernikusControlPlaneReconciler) getCredentials(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane, cluster *capiv1beta1.Cluster) (*kubernikus.Credentials, error) {
+	key, err := r.credentialsSecretKey(ctx, kcp, cluster)
+	if err != nil {
+		var identityErr *identityError
+		if stderrors.As(err, &identityErr) {
+			meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+				Type:    controlplanev1alpha1.CredentialsValidCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  identityErr.reason,
+				Message: identityErr.msg,
+			})
+			return nil, &credentialsError{err: err}
+		}
+		return nil, err
+	}
+	var sec v1.Secret
+	err = r.Get(ctx, key, &sec)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+				Type:    controlplanev1alpha1.CredentialsValidCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  controlplanev1alpha1.CredentialsSecretNotFoundReason,
+				Message: fmt.Sprintf("Secret %s not found", key),
+			})
+			return nil, &credentialsError{err: err}
+		}
+		return nil, err
 	}
-	return ctrl.Result{Requeue: true}, nil
+	creds, err := kubernikus.CredentialsFromSecret(&sec)
+	if err == nil && creds.AuthMode == kubernikus.AuthModeTokenFile {
+		// the token file is read with the permissions of the controller, e.g. its service account token,
+		// only the secrets of identities in the controller namespace may use it
+		if kcp.Spec.IdentityRef == nil || key.Namespace != r.IdentityNamespace {
+			err = fmt.Errorf("invalid credentials in secret %s: auth mode %s is only allowed for KubernikusIdentities",
+				key, kubernikus.AuthModeTokenFile)
+		} else {
+			err = creds.CheckTokenFile(r.TokenFileDirs)
+		}
+	}
+	if err != nil {
+		meta.SetStatusCondition(&kcp.Status.Conditions, metav1.Condition{
+			Type:    controlplanev1alpha1.CredentialsValidCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  controlplanev1alpha1.InvalidCredentialsReason,
+			Message: err.Error(),
+		})
+		return nil, &credentialsError{err: err}
+	}
+	original := sec.DeepCopy()
+	labeled := setSecretLabel(&sec)
+	// secrets of identities are not moved, identities are global and set up in every management cluster
+	if sec.Namespace == kcp.Namespace && setMoveLabel(&sec) {
+		labeled = true
+	}
+	if labeled {
+		err = r.Patch(ctx, &sec, client.MergeFrom(original))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return creds, nil
+}
+
+// setFailure records a terminal kubernikus error in the status of the control plane.
+// Callers requeue at the periodic interval instead of retrying with backoff,
+// as the error only goes away once the spec or the project in kubernikus changes.
+func (r *KubernikusControlPlaneReconciler) setFailure(kcp *controlplanev1alpha1.KubernikusControlPlane, failure *kubernikus.TerminalError) {
+	if kcp.Status.FailureReason != failure.Reason || kcp.Status.FailureMessage != failure.Message {
+		r.Recorder.Eventf(kcp, v1.EventTypeWarning, failure.Reason, "Kubernikus rejected kluster %s: %s", kcp.Name, failure.Message)
+	}
+	kcp.Status.FailureReason = failure.Reason
+	kcp.Status.FailureMessage = failure.Message
+}
+
+// controllerRef returns the owner reference making kcp the controller of the secrets of its cluster.
+func controllerRef(kcp *controlplanev1alpha1.KubernikusControlPlane) metav1.OwnerReference {
+	return *metav1.NewControllerRef(kcp, controlplanev1alpha1.GroupVersion.WithKind("KubernikusControlPlane"))
+}
+
+// setMoveLabel labels sec for clusterctl move and reports whether the label was missing.
+func setMoveLabel(sec *v1.Secret) bool {
+	return setLabel(sec, clusterctlv1.ClusterctlMoveLabel)
+}
+
+// setSecretLabel labels sec to be watched by the controller and reports whether the label was missing.
+func setSecretLabel(sec *v1.Secret) bool {
+	return setLabel(sec, controlplanev1alpha1.SecretLabel)
+}
+
+func setLabel(sec *v1.Secret, label string) bool {
+	if _, ok := sec.Labels[label]; ok {
+		return false
+	}
+	if sec.Labels == nil {
+		sec.Labels = map[string]string{}
+	}
+	sec.Labels[label] = ""
+	return true
+}
+
+// adoptSecrets makes the control plane the controller of the kubeconfig, CA and service account secrets
+// of the owner cluster, and labels them for clusterctl move. Secrets created by earlier versions are
+// owned by the cluster or by the control plane without controlling them.
+func (r *KubernikusControlPlaneReconciler) adoptSecrets(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane, cluster *capiv1beta1.Cluster) error {
+	for _, purpose := range []secret.Purpose{secret.Kubeconfig, secret.ClusterCA, secret.ServiceAccount} {
+		sec, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), purpose)
+		if err != nil {
+			return err
+		}
+		original := sec.DeepCopy()
+		sec.OwnerReferences = slices.DeleteFunc(sec.OwnerReferences, func(ref metav1.OwnerReference) bool {
+			return ref.Kind == "Cluster" && ref.Name == cluster.Name && strings.HasPrefix(ref.APIVersion, capiv1beta1.GroupVersion.Group+"/")
+		})
+		err = controllerutil.SetControllerReference(kcp, sec, r.Scheme)
+		if err != nil {
+			return err
+		}
+		setMoveLabel(sec)
+		setSecretLabel(sec)
+		if equality.Semantic.DeepEqual(original.ObjectMeta, sec.ObjectMeta) {
+			continue
+		}
+		err = r.Patch(ctx, sec, client.MergeFrom(original))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retainSecret drops the owner references of a secret, so it survives the garbage collection of the cluster.
+func (r *KubernikusControlPlaneReconciler) retainSecret(ctx context.Context, key client.ObjectKey) error {
+	var sec v1.Secret
+	err := r.Get(ctx, key, &sec)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if len(sec.OwnerReferences) == 0 {
+		return nil
+	}
+	patch := client.MergeFrom(sec.DeepCopy())
+	sec.OwnerReferences = nil
+	return r.Patch(ctx, &sec, patch)
+}
+
+// removeFinalizer releases the KubernikusControlPlane so it can be removed by the api server.
+func (r *KubernikusControlPlaneReconciler) removeFinalizer(ctx context.Context, kcp *controlplanev1alpha1.KubernikusControlPlane) error {
+	if controllerutil.RemoveFinalizer(kcp, controlplanev1alpha1.KubernikusControlPlaneFinalizer) {
+		return r.Update(ctx, kcp)
+	}
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *KubernikusControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.NewKubernikusAPI == nil {
+		if r.Clients == nil {
+			r.Clients = kubernikus.NewClientPool(kubernikus.DefaultClientIdleTimeout, kubernikus.TransportOptions{})
+		}
+		r.NewKubernikusAPI = r.Clients.API
+	}
+	err := r.setupIndexes(context.Background(), mgr)
+	if err != nil {
+		return err
+	}
+
+	logger := mgr.GetLogger().WithValues("controller", "kubernikuscontrolplane")
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&controlplanev1alpha1.KubernikusControlPlane{}).
+		For(&controlplanev1alpha1.KubernikusControlPlane{}, builder.WithPredicates(
+			predicates.ResourceHasFilterLabel(mgr.GetScheme(), logger, r.WatchFilterValue),
+			statusUnchanged(),
+		)).
+		Watches(&capiv1beta1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(r.clusterToKubernikusControlPlane),
+			builder.WithPredicates(
+				predicates.ResourceHasFilterLabel(mgr.GetScheme(), logger, r.WatchFilterValue),
+				predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{}),
+			)).
+		Owns(&v1.Secret{}, builder.WithPredicates(secretDataChanged())).
+		Watches(&v1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.secretToKubernikusControlPlanes),
+			builder.WithPredicates(secretDataChanged())).
+		Watches(&controlplanev1alpha1.KubernikusIdentity{},
+			handler.EnqueueRequestsFromMapFunc(r.identityToKubernikusControlPlanes),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
-
-// convertSecret takes a v1.Secret and converts it to a string map for use in the kubernikus client
-// TODO: revisit this and create a proper struct
-func convertSecret(sec *v1.Secret) map[string]string {
-	ret := make(map[string]string)
-	for k, v := range sec.Data {
-		ret[k] = strings.TrimSuffix(string(v), "\n")
-	}
-	return ret
-}
@@ -0,0 +1,805 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sapcc/kubernikus/pkg/api/models"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/fakekubernikus"
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+const (
+	eventuallyTimeout = 30 * time.Second
+	pollingInterval   = 250 * time.Millisecond
+)
+
+// controlPlane is a Cluster with its KubernikusControlPlane in a namespace of its own.
+type controlPlane struct {
+	cluster *capiv1beta1.Cluster
+	kcp     *controlplanev1alpha1.KubernikusControlPlane
+}
+
+// newControlPlane creates a Cluster and its KubernikusControlPlane called name. The Cluster uses the
+// credentials secret named after it, which is created from credentials unless they are nil.
+// The objects are passed to mutate before they are created.
+func newControlPlane(name string, credentials *v1.Secret, mutate ...func(*capiv1beta1.Cluster, *controlplanev1alpha1.KubernikusControlPlane)) *controlPlane {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: name + "-"}}
+	Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+
+	if credentials != nil {
+		credentials.Namespace = ns.Name
+		credentials.Name = name
+		Expect(k8sClient.Create(ctx, credentials)).To(Succeed())
+	}
+
+	cluster := &capiv1beta1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, Name: name},
+		Spec: capiv1beta1.ClusterSpec{
+			ControlPlaneRef: &v1.ObjectReference{
+				APIVersion: controlplanev1alpha1.GroupVersion.String(),
+				Kind:       "KubernikusControlPlane",
+				Namespace:  ns.Name,
+				Name:       name,
+			},
+		},
+	}
+	kcp := &controlplanev1alpha1.KubernikusControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, Name: name},
+		Spec:       controlplanev1alpha1.KubernikusControlPlaneSpec{Version: "v1.32.1"},
+	}
+	for _, f := range mutate {
+		f(cluster, kcp)
+	}
+
+	Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+	kcp.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: capiv1beta1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	}}
+	Expect(k8sClient.Create(ctx, kcp)).To(Succeed())
+	return &controlPlane{cluster: cluster, kcp: kcp}
+}
+
+// eventually polls assertion until it succeeds. Every attempt touches the control plane first,
+// so the reconciler does not depend on its requeue intervals to observe changes of the fake kubernikus.
+func (cp *controlPlane) eventually(assertion func(g Gomega)) {
+	GinkgoHelper()
+	Eventually(func(g Gomega) {
+		cp.poke()
+		assertion(g)
+	}).WithTimeout(eventuallyTimeout).WithPolling(pollingInterval).Should(Succeed())
+}
+
+func (cp *controlPlane) poke() {
+	var kcp controlplanev1alpha1.KubernikusControlPlane
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.kcp), &kcp); err != nil {
+		return
+	}
+	patch := client.MergeFrom(kcp.DeepCopy())
+	if kcp.Annotations == nil {
+		kcp.Annotations = map[string]string{}
+	}
+	kcp.Annotations["test.kubernikus.cloud.sap/poke"] = time.Now().Format(time.RFC3339Nano)
+	_ = k8sClient.Patch(ctx, &kcp, patch)
+}
+
+// get returns the current state of the control plane.
+func (cp *controlPlane) get(g Gomega) *controlplanev1alpha1.KubernikusControlPlane {
+	var kcp controlplanev1alpha1.KubernikusControlPlane
+	g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.kcp), &kcp)).To(Succeed())
+	return &kcp
+}
+
+// secret returns the secret of the cluster with the purpose.
+func (cp *controlPlane) secret(g Gomega, purpose secret.Purpose) *v1.Secret {
+	var sec v1.Secret
+	key := client.ObjectKey{Namespace: cp.cluster.Namespace, Name: secret.Name(cp.cluster.Name, purpose)}
+	g.Expect(k8sClient.Get(ctx, key, &sec)).To(Succeed())
+	return &sec
+}
+
+func haveCondition(conditionType string, status metav1.ConditionStatus, reason string) OmegaMatcher {
+	return WithTransform(func(kcp *controlplanev1alpha1.KubernikusControlPlane) *metav1.Condition {
+		return meta.FindStatusCondition(kcp.Status.Conditions, conditionType)
+	}, And(
+		Not(BeNil()),
+		HaveField("Status", status),
+		HaveField("Reason", reason),
+	))
+}
+
+// beMovedWith matches secrets controlled by kcp and labeled for clusterctl move.
+func beMovedWith(kcp *controlplanev1alpha1.KubernikusControlPlane) OmegaMatcher {
+	return And(
+		HaveField("Labels", HaveKey(clusterctlv1.ClusterctlMoveLabel)),
+		WithTransform(metav1.GetControllerOf, And(
+			Not(BeNil()),
+			HaveField("Kind", "KubernikusControlPlane"),
+			HaveField("UID", kcp.UID),
+		)),
+	)
+}
+
+// clientCertificate returns the client certificate of the kubeconfig in sec.
+func clientCertificate(g Gomega, sec *v1.Secret) *x509.Certificate {
+	config, err := clientcmd.Load(sec.Data[secret.KubeconfigDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	authInfo := config.AuthInfos[config.Contexts[config.CurrentContext].AuthInfo]
+	g.Expect(authInfo).NotTo(BeNil())
+	block, _ := pem.Decode(authInfo.ClientCertificateData)
+	g.Expect(block).NotTo(BeNil())
+	cert, err := x509.ParseCertificate(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	return cert
+}
+
+var _ = Describe("KubernikusControlPlane controller", func() {
+	Describe("lifecycle", Ordered, func() {
+		var cp *controlPlane
+
+		BeforeAll(func() {
+			cp = newControlPlane("lifecycle", fakeKKS.CredentialsSecret("", ""))
+		})
+
+		It("creates the kluster", func() {
+			cp.eventually(func(g Gomega) {
+				kluster, ok := fakeKKS.Kluster("lifecycle")
+				g.Expect(ok).To(BeTrue())
+				g.Expect(kluster.Spec.Version).To(Equal("1.32.1"))
+
+				kcp := cp.get(g)
+				g.Expect(controllerutil.ContainsFinalizer(kcp, controlplanev1alpha1.KubernikusControlPlaneFinalizer)).To(BeTrue())
+				g.Expect(kcp.Status.Ready).To(BeFalse())
+				g.Expect(kcp.Status.Initialized).To(BeFalse())
+				g.Expect(kcp.Status.Initialization).To(BeNil())
+				g.Expect(kcp.Status.Phase).To(Equal(string(models.KlusterPhasePending)))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.CredentialsValidCondition, metav1.ConditionTrue, controlplanev1alpha1.CredentialsValidReason))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.KlusterProvisionedCondition, metav1.ConditionFalse, controlplanev1alpha1.KlusterProvisioningReason))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.ReadyCondition, metav1.ConditionFalse, controlplanev1alpha1.NotReadyReason))
+			})
+		})
+
+		It("waits for the kluster to be ready", func() {
+			fakeClock.Add(2 * time.Minute)
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				g.Expect(kcp.Status.Ready).To(BeTrue())
+				g.Expect(kcp.Status.Initialized).To(BeTrue())
+				g.Expect(kcp.Status.Initialization.ControlPlaneInitialized).To(HaveValue(BeTrue()))
+				g.Expect(kcp.Status.Version).To(Equal("v1.32.1"))
+				g.Expect(kcp.Status.Phase).To(Equal(string(models.KlusterPhaseRunning)))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.ReadyCondition, metav1.ConditionTrue, controlplanev1alpha1.ReadyReason))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.AvailableCondition, metav1.ConditionTrue, controlplanev1alpha1.AvailableReason))
+			})
+		})
+
+		It("propagates the endpoint to the cluster", func() {
+			cp.eventually(func(g Gomega) {
+				var cluster capiv1beta1.Cluster
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+				g.Expect(cluster.Spec.ControlPlaneEndpoint.Host).To(Equal("lifecycle." + fakekubernikus.DefaultDomain))
+				g.Expect(cluster.Spec.ControlPlaneEndpoint.Port).To(BeEquivalentTo(443))
+			})
+		})
+
+		It("creates the kubeconfig and CA secrets", func() {
+			cp.eventually(func(g Gomega) {
+				kubeconfig := cp.secret(g, secret.Kubeconfig)
+				config, err := clientcmd.Load(kubeconfig.Data[secret.KubeconfigDataName])
+				g.Expect(err).NotTo(HaveOccurred())
+				cluster := config.Clusters[config.Contexts[config.CurrentContext].Cluster]
+				g.Expect(cluster.Server).To(Equal("https://lifecycle." + fakekubernikus.DefaultDomain))
+
+				ca := cp.secret(g, secret.ClusterCA)
+				_, err = tls.X509KeyPair(ca.Data[secret.TLSCrtDataName], ca.Data[secret.TLSKeyDataName])
+				g.Expect(err).NotTo(HaveOccurred(), "the CA secret holds a matching key pair")
+				g.Expect(ca.Data[secret.TLSCrtDataName]).To(Equal(cluster.CertificateAuthorityData))
+
+				sa := cp.secret(g, secret.ServiceAccount)
+				g.Expect(sa.Data).To(HaveKey(secret.TLSKeyDataName))
+
+				kcp := cp.get(g)
+				for _, sec := range []*v1.Secret{kubeconfig, ca, sa} {
+					g.Expect(sec).To(beMovedWith(kcp))
+				}
+				var credentials v1.Secret
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &credentials)).To(Succeed())
+				g.Expect(credentials.Labels).To(HaveKey(clusterctlv1.ClusterctlMoveLabel))
+
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.CertificatesAvailableCondition, metav1.ConditionTrue, controlplanev1alpha1.CertificatesAvailableReason))
+			})
+		})
+
+		It("upgrades the kluster", func() {
+			Eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				kcp.Spec.Version = "v1.33.0"
+				g.Expect(k8sClient.Update(ctx, kcp)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+
+			cp.eventually(func(g Gomega) {
+				kluster, _ := fakeKKS.Kluster("lifecycle")
+				g.Expect(kluster.Spec.Version).To(Equal("1.33.0"))
+				g.Expect(kluster.Status.Phase).To(Equal(models.KlusterPhaseUpgrading))
+				kcp := cp.get(g)
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.UpgradeInProgressCondition, metav1.ConditionTrue, controlplanev1alpha1.UpgradingReason))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.RollingOutCondition, metav1.ConditionTrue, controlplanev1alpha1.RollingOutReason))
+			})
+
+			fakeClock.Add(time.Minute)
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				g.Expect(kcp.Status.Version).To(Equal("v1.33.0"))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.UpgradeInProgressCondition, metav1.ConditionFalse, controlplanev1alpha1.UpToDateReason))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.ReadyCondition, metav1.ConditionTrue, controlplanev1alpha1.ReadyReason))
+			})
+		})
+
+		It("terminates the kluster on deletion", func() {
+			Expect(k8sClient.Delete(ctx, cp.kcp)).To(Succeed())
+
+			cp.eventually(func(g Gomega) {
+				kluster, ok := fakeKKS.Kluster("lifecycle")
+				g.Expect(ok).To(BeTrue())
+				g.Expect(kluster.Status.Phase).To(Equal(models.KlusterPhaseTerminating))
+				kcp := cp.get(g)
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.DeletingCondition, metav1.ConditionTrue, controlplanev1alpha1.KlusterTerminatingReason))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.ReadyCondition, metav1.ConditionFalse, controlplanev1alpha1.KlusterTerminatingReason))
+			})
+
+			fakeClock.Add(time.Minute)
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("lifecycle")
+				g.Expect(ok).To(BeFalse())
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.kcp), &controlplanev1alpha1.KubernikusControlPlane{})
+				g.Expect(errors.IsNotFound(err)).To(BeTrue())
+				for _, purpose := range []secret.Purpose{secret.Kubeconfig, secret.ClusterCA, secret.ServiceAccount} {
+					key := client.ObjectKey{Namespace: cp.cluster.Namespace, Name: secret.Name(cp.cluster.Name, purpose)}
+					err := k8sClient.Get(ctx, key, &v1.Secret{})
+					g.Expect(errors.IsNotFound(err)).To(BeTrue(), "secret %s is deleted", key.Name)
+				}
+			})
+		})
+	})
+
+	Describe("kubeconfig rotation", func() {
+		It("replaces kubeconfigs whose client certificate is about to expire", func() {
+			// the client certificates expire before the rotation threshold, so every reconcile rotates
+			shortLived := fakekubernikus.NewTestServer(fakekubernikus.Options{CertificateValidity: 20 * time.Minute})
+			DeferCleanup(shortLived.Close)
+			cp := newControlPlane("rotation", shortLived.CredentialsSecret("", ""))
+
+			var first *x509.Certificate
+			cp.eventually(func(g Gomega) {
+				first = clientCertificate(g, cp.secret(g, secret.Kubeconfig))
+			})
+			cp.eventually(func(g Gomega) {
+				rotated := clientCertificate(g, cp.secret(g, secret.Kubeconfig))
+				g.Expect(rotated.SerialNumber).NotTo(Equal(first.SerialNumber))
+			})
+			Expect(shortLived.Requests(fakekubernikus.GetClusterCredentials)).To(BeNumerically(">", 1))
+		})
+	})
+
+	Describe("watches", func() {
+		It("reconciles the control plane when its cluster changes", func() {
+			cp := newControlPlane("cluster-watch", fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("cluster-watch")
+				g.Expect(ok).To(BeTrue())
+			})
+			fakeClock.Add(2 * time.Minute)
+			cp.eventually(func(g Gomega) {
+				var cluster capiv1beta1.Cluster
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+				g.Expect(cluster.Spec.ControlPlaneEndpoint.Host).NotTo(BeEmpty())
+			})
+			// let the reconciles triggered by the pokes finish
+			Eventually(func(g Gomega) {
+				resourceVersion := cp.get(g).ResourceVersion
+				g.Consistently(func(g Gomega) {
+					g.Expect(cp.get(g).ResourceVersion).To(Equal(resourceVersion))
+				}).WithTimeout(time.Second).WithPolling(100 * time.Millisecond).Should(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var cluster capiv1beta1.Cluster
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+				cluster.Spec.ControlPlaneEndpoint = capiv1beta1.APIEndpoint{}
+				g.Expect(k8sClient.Update(ctx, &cluster)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+
+			// the running interval is far longer than the timeout, only the watch can restore the endpoint
+			Eventually(func(g Gomega) {
+				var cluster capiv1beta1.Cluster
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+				g.Expect(cluster.Spec.ControlPlaneEndpoint.Host).To(Equal("cluster-watch." + fakekubernikus.DefaultDomain))
+			}).WithTimeout(eventuallyTimeout).WithPolling(pollingInterval).Should(Succeed())
+		})
+
+		It("recreates the kubeconfig secret when it is deleted", func() {
+			cp := newControlPlane("secret-watch", fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("secret-watch")
+				g.Expect(ok).To(BeTrue())
+			})
+			fakeClock.Add(2 * time.Minute)
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.secret(g, secret.Kubeconfig).Labels).To(HaveKey(controlplanev1alpha1.SecretLabel))
+				var credentials v1.Secret
+				g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: cp.cluster.Namespace, Name: cp.cluster.Name}, &credentials)).To(Succeed())
+				g.Expect(credentials.Labels).To(HaveKey(controlplanev1alpha1.SecretLabel))
+			})
+			// let the reconciles triggered by the pokes finish
+			Eventually(func(g Gomega) {
+				resourceVersion := cp.get(g).ResourceVersion
+				g.Consistently(func(g Gomega) {
+					g.Expect(cp.get(g).ResourceVersion).To(Equal(resourceVersion))
+				}).WithTimeout(time.Second).WithPolling(100 * time.Millisecond).Should(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+
+			Expect(k8sClient.Delete(ctx, &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Namespace: cp.cluster.Namespace,
+				Name:      secret.Name(cp.cluster.Name, secret.Kubeconfig),
+			}})).To(Succeed())
+			// the running interval is far longer than the timeout, only the watch can restore the secret
+			Eventually(func(g Gomega) {
+				g.Expect(cp.secret(g, secret.Kubeconfig).Data).To(HaveKey(secret.KubeconfigDataName))
+			}).WithTimeout(eventuallyTimeout).WithPolling(pollingInterval).Should(Succeed())
+		})
+	})
+
+	Describe("pause", func() {
+		It("does not update the kluster while the cluster is paused", func() {
+			cp := newControlPlane("paused", fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("paused")
+				g.Expect(ok).To(BeTrue())
+			})
+			fakeClock.Add(2 * time.Minute)
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g).Status.Ready).To(BeTrue())
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.PausedCondition, metav1.ConditionFalse, controlplanev1alpha1.NotPausedReason))
+			})
+
+			setPaused := func(paused bool) {
+				Eventually(func(g Gomega) {
+					var cluster capiv1beta1.Cluster
+					g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+					cluster.Spec.Paused = paused
+					g.Expect(k8sClient.Update(ctx, &cluster)).To(Succeed())
+				}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			}
+			setPaused(true)
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.PausedCondition, metav1.ConditionTrue, controlplanev1alpha1.PausedReason))
+			})
+			updates := fakeKKS.Requests(fakekubernikus.UpdateCluster)
+
+			Eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				kcp.Spec.Version = "v1.33.0"
+				g.Expect(k8sClient.Update(ctx, kcp)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			Consistently(func(g Gomega) {
+				cp.poke()
+				kluster, _ := fakeKKS.Kluster("paused")
+				g.Expect(kluster.Spec.Version).To(Equal("1.32.1"))
+			}).WithTimeout(2 * time.Second).WithPolling(pollingInterval).Should(Succeed())
+			Expect(fakeKKS.Requests(fakekubernikus.UpdateCluster)).To(Equal(updates))
+
+			setPaused(false)
+			cp.eventually(func(g Gomega) {
+				kluster, _ := fakeKKS.Kluster("paused")
+				g.Expect(kluster.Spec.Version).To(Equal("1.33.0"))
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.PausedCondition, metav1.ConditionFalse, controlplanev1alpha1.NotPausedReason))
+			})
+		})
+
+		It("reports why a new control plane is paused and does not create its kluster", func() {
+			cp := newControlPlane("paused-new", fakeKKS.CredentialsSecret("", ""), func(cluster *capiv1beta1.Cluster, kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				cluster.Spec.Paused = true
+				kcp.Annotations = map[string]string{capiv1beta1.PausedAnnotation: ""}
+			})
+			pausedWith := func(message string) {
+				GinkgoHelper()
+				cp.eventually(func(g Gomega) {
+					kcp := cp.get(g)
+					g.Expect(kcp).To(haveCondition(controlplanev1alpha1.PausedCondition, metav1.ConditionTrue, controlplanev1alpha1.PausedReason))
+					g.Expect(meta.FindStatusCondition(kcp.Status.Conditions, controlplanev1alpha1.PausedCondition).Message).To(Equal(message))
+					g.Expect(kcp.Finalizers).To(BeEmpty())
+				})
+				_, ok := fakeKKS.Kluster("paused-new")
+				Expect(ok).To(BeFalse())
+			}
+			pausedWith("Cluster spec.paused is set to true, KubernikusControlPlane has the cluster.x-k8s.io/paused annotation")
+
+			Eventually(func(g Gomega) {
+				var cluster capiv1beta1.Cluster
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+				cluster.Spec.Paused = false
+				g.Expect(k8sClient.Update(ctx, &cluster)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			pausedWith("KubernikusControlPlane has the cluster.x-k8s.io/paused annotation")
+
+			Eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				delete(kcp.Annotations, capiv1beta1.PausedAnnotation)
+				g.Expect(k8sClient.Update(ctx, kcp)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("paused-new")
+				g.Expect(ok).To(BeTrue())
+				kcp := cp.get(g)
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.PausedCondition, metav1.ConditionFalse, controlplanev1alpha1.NotPausedReason))
+				g.Expect(kcp.Finalizers).To(ContainElement(controlplanev1alpha1.KubernikusControlPlaneFinalizer))
+			})
+		})
+	})
+
+	Describe("clusterctl move", func() {
+		// moveTo copies the objects of cp into a new namespace like clusterctl move does: owner references
+		// are rewritten to the new objects and the cluster stays paused until the source objects are gone.
+		moveTo := func(cp *controlPlane) *controlPlane {
+			GinkgoHelper()
+			ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: cp.cluster.Name + "-target-"}}
+			Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+
+			var cluster capiv1beta1.Cluster
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+			Expect(cluster.Spec.Paused).To(BeTrue())
+			target := &capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, Name: cluster.Name, Labels: cluster.Labels},
+				Spec:       *cluster.Spec.DeepCopy(),
+			}
+			target.Spec.ControlPlaneRef.Namespace = ns.Name
+			Expect(k8sClient.Create(ctx, target)).To(Succeed())
+
+			source := &controlplanev1alpha1.KubernikusControlPlane{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.kcp), source)).To(Succeed())
+			kcp := &controlplanev1alpha1.KubernikusControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  ns.Name,
+					Name:       source.Name,
+					Finalizers: source.Finalizers,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: capiv1beta1.GroupVersion.String(),
+						Kind:       "Cluster",
+						Name:       target.Name,
+						UID:        target.UID,
+					}},
+				},
+				Spec: source.Spec,
+			}
+			Expect(k8sClient.Create(ctx, kcp)).To(Succeed())
+
+			var secrets v1.SecretList
+			Expect(k8sClient.List(ctx, &secrets, client.InNamespace(cluster.Namespace), client.HasLabels{clusterctlv1.ClusterctlMoveLabel})).To(Succeed())
+			Expect(secrets.Items).To(HaveLen(4), "the credentials, kubeconfig, CA and service account secrets are moved")
+			for _, sec := range secrets.Items {
+				moved := &v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, Name: sec.Name, Labels: sec.Labels},
+					Type:       sec.Type,
+					Data:       sec.Data,
+				}
+				for _, ref := range sec.OwnerReferences {
+					Expect(ref.UID).To(Equal(source.UID))
+					ref.UID = kcp.UID
+					moved.OwnerReferences = append(moved.OwnerReferences, ref)
+				}
+				Expect(k8sClient.Create(ctx, moved)).To(Succeed())
+			}
+
+			// clusterctl removes the finalizers of the source objects before deleting them
+			patch := client.MergeFrom(source.DeepCopy())
+			source.Finalizers = nil
+			Expect(k8sClient.Patch(ctx, source, patch)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, source)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &cluster)).To(Succeed())
+
+			patch = client.MergeFrom(target.DeepCopy())
+			target.Spec.Paused = false
+			Expect(k8sClient.Patch(ctx, target, patch)).To(Succeed())
+			return &controlPlane{cluster: target, kcp: kcp}
+		}
+
+		It("adopts secrets created by earlier versions", func() {
+			cp := newControlPlane("legacy-secrets", fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("legacy-secrets")
+				g.Expect(ok).To(BeTrue())
+			})
+			fakeClock.Add(2 * time.Minute)
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				for _, purpose := range []secret.Purpose{secret.Kubeconfig, secret.ClusterCA, secret.ServiceAccount} {
+					g.Expect(cp.secret(g, purpose)).To(beMovedWith(kcp))
+				}
+			})
+
+			// earlier versions let the Cluster own the kubeconfig and did not label the secrets for move
+			kcp := cp.get(Default)
+			for purpose, owner := range map[secret.Purpose]metav1.OwnerReference{
+				secret.Kubeconfig: {APIVersion: capiv1beta1.GroupVersion.String(), Kind: "Cluster", Name: cp.cluster.Name, UID: cp.cluster.UID},
+				secret.ClusterCA:  {APIVersion: controlplanev1alpha1.GroupVersion.String(), Kind: "KubernikusControlPlane", Name: kcp.Name, UID: kcp.UID},
+			} {
+				Eventually(func(g Gomega) {
+					sec := cp.secret(g, purpose)
+					sec.OwnerReferences = []metav1.OwnerReference{owner}
+					delete(sec.Labels, clusterctlv1.ClusterctlMoveLabel)
+					g.Expect(k8sClient.Update(ctx, sec)).To(Succeed())
+				}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			}
+
+			cp.eventually(func(g Gomega) {
+				for _, purpose := range []secret.Purpose{secret.Kubeconfig, secret.ClusterCA} {
+					sec := cp.secret(g, purpose)
+					g.Expect(sec).To(beMovedWith(kcp))
+					g.Expect(sec.OwnerReferences).To(HaveLen(1))
+					g.Expect(sec.Labels).To(HaveKeyWithValue(capiv1beta1.ClusterNameLabel, cp.cluster.Name))
+				}
+			})
+		})
+
+		It("adopts the kluster and the secrets in the target namespace", func() {
+			cp := newControlPlane("moved", fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("moved")
+				g.Expect(ok).To(BeTrue())
+			})
+			fakeClock.Add(2 * time.Minute)
+			var ca, kubeconfig *v1.Secret
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.ReadyCondition, metav1.ConditionTrue, controlplanev1alpha1.ReadyReason))
+				ca = cp.secret(g, secret.ClusterCA)
+				kubeconfig = cp.secret(g, secret.Kubeconfig)
+				g.Expect(ca).To(beMovedWith(kcp))
+			})
+
+			Eventually(func(g Gomega) {
+				var cluster capiv1beta1.Cluster
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), &cluster)).To(Succeed())
+				cluster.Spec.Paused = true
+				g.Expect(k8sClient.Update(ctx, &cluster)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.PausedCondition, metav1.ConditionTrue, controlplanev1alpha1.PausedReason))
+			})
+			creates := fakeKKS.Requests(fakekubernikus.CreateCluster)
+
+			moved := moveTo(cp)
+			moved.eventually(func(g Gomega) {
+				kcp := moved.get(g)
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.ReadyCondition, metav1.ConditionTrue, controlplanev1alpha1.ReadyReason))
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.PausedCondition, metav1.ConditionFalse, controlplanev1alpha1.NotPausedReason))
+				g.Expect(moved.secret(g, secret.ClusterCA).Data).To(Equal(ca.Data))
+				g.Expect(moved.secret(g, secret.Kubeconfig).Data).To(Equal(kubeconfig.Data))
+				for _, purpose := range []secret.Purpose{secret.Kubeconfig, secret.ClusterCA, secret.ServiceAccount} {
+					g.Expect(moved.secret(g, purpose)).To(beMovedWith(kcp))
+				}
+			})
+			Expect(fakeKKS.Requests(fakekubernikus.CreateCluster)).To(Equal(creates))
+			kluster, ok := fakeKKS.Kluster("moved")
+			Expect(ok).To(BeTrue())
+			Expect(kluster.Status.Phase).To(Equal(models.KlusterPhaseRunning))
+		})
+	})
+
+	Describe("kubernikus errors", func() {
+		It("retries version changes until the kluster is running", func() {
+			cp := newControlPlane("early-upgrade", fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				kluster, ok := fakeKKS.Kluster("early-upgrade")
+				g.Expect(ok).To(BeTrue())
+				g.Expect(kluster.Status.Phase).NotTo(Equal(models.KlusterPhaseRunning))
+			})
+			Eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				kcp.Spec.Version = "v1.33.0"
+				g.Expect(k8sClient.Update(ctx, kcp)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+
+			// kubernikus only changes the version of running klusters
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.KlusterProvisionedCondition, metav1.ConditionFalse, controlplanev1alpha1.KlusterProvisioningFailedReason))
+				g.Expect(kcp.Status.FailureReason).To(BeEmpty())
+			})
+
+			fakeClock.Add(2 * time.Minute)
+			cp.eventually(func(g Gomega) {
+				kluster, _ := fakeKKS.Kluster("early-upgrade")
+				g.Expect(kluster.Spec.Version).To(Equal("1.33.0"))
+				g.Expect(cp.get(g).Status.FailureReason).To(BeEmpty())
+			})
+		})
+
+		It("clears the failure once kubernikus accepts the spec", func() {
+			cp := newControlPlane("rejected-spec", fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("rejected-spec")
+				g.Expect(ok).To(BeTrue())
+			})
+			fakeKKS.InjectError(fakekubernikus.UpdateCluster, fakekubernikus.Error{Code: 400, Message: "invalid ssh public key"})
+			DeferCleanup(fakeKKS.ClearErrors)
+			Eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				kcp.Spec.SSHPublicKey = "ssh-ed25519 AAAA"
+				g.Expect(k8sClient.Update(ctx, kcp)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				g.Expect(kcp.Status.FailureReason).To(Equal(kubernikus.InvalidSpecFailure))
+				g.Expect(kcp.Status.FailureMessage).To(Equal("invalid ssh public key"))
+			})
+
+			fakeKKS.ClearErrors()
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				g.Expect(kcp.Status.FailureReason).To(BeEmpty())
+				g.Expect(kcp.Status.FailureMessage).To(BeEmpty())
+				kluster, _ := fakeKKS.Kluster("rejected-spec")
+				g.Expect(kluster.Spec.SSHPublicKey).To(Equal("ssh-ed25519 AAAA"))
+			})
+		})
+	})
+
+	Describe("deletion", func() {
+		// provisioned creates a control plane and waits for its kluster
+		provisioned := func(name string) *controlPlane {
+			GinkgoHelper()
+			cp := newControlPlane(name, fakeKKS.CredentialsSecret("", ""))
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster(name)
+				g.Expect(ok).To(BeTrue())
+				g.Expect(cp.get(g).Finalizers).To(ContainElement(controlplanev1alpha1.KubernikusControlPlaneFinalizer))
+			})
+			return cp
+		}
+		released := func(g Gomega, cp *controlPlane) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.kcp), &controlplanev1alpha1.KubernikusControlPlane{})
+			g.Expect(errors.IsNotFound(err)).To(BeTrue())
+		}
+
+		It("terminates the kluster when the cluster is deleted first", func() {
+			cp := provisioned("cluster-gone")
+			Expect(k8sClient.Delete(ctx, cp.cluster)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cp.kcp)).To(Succeed())
+
+			cp.eventually(func(g Gomega) {
+				kluster, ok := fakeKKS.Kluster("cluster-gone")
+				g.Expect(ok).To(BeTrue())
+				g.Expect(kluster.Status.Phase).To(Equal(models.KlusterPhaseTerminating))
+			})
+			fakeClock.Add(time.Minute)
+			cp.eventually(func(g Gomega) {
+				_, ok := fakeKKS.Kluster("cluster-gone")
+				g.Expect(ok).To(BeFalse())
+				released(g, cp)
+			})
+		})
+
+		It("blocks the deletion while the credentials are missing", func() {
+			cp := provisioned("deletion-blocked")
+			credentials := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), credentials)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, credentials)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cp.kcp)).To(Succeed())
+
+			cp.eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				g.Expect(kcp).To(haveCondition(controlplanev1alpha1.DeletingCondition, metav1.ConditionTrue, controlplanev1alpha1.DeletionBlockedReason))
+				g.Expect(meta.FindStatusCondition(kcp.Status.Conditions, controlplanev1alpha1.DeletingCondition).Message).
+					To(ContainSubstring(controlplanev1alpha1.KubernikusControlPlaneFinalizer))
+			})
+			kluster, ok := fakeKKS.Kluster("deletion-blocked")
+			Expect(ok).To(BeTrue())
+			Expect(kluster.Status.Phase).NotTo(Equal(models.KlusterPhaseTerminating))
+
+			Expect(k8sClient.Create(ctx, fakeKKS.CredentialsSecret(cp.cluster.Namespace, cp.cluster.Name))).To(Succeed())
+			cp.eventually(func(g Gomega) {
+				kluster, ok := fakeKKS.Kluster("deletion-blocked")
+				g.Expect(ok).To(BeTrue())
+				g.Expect(kluster.Status.Phase).To(Equal(models.KlusterPhaseTerminating))
+			})
+			fakeClock.Add(time.Minute)
+			cp.eventually(func(g Gomega) {
+				released(g, cp)
+			})
+		})
+
+		It("orphans the kluster without credentials", func() {
+			cp := provisioned("orphaned")
+			Eventually(func(g Gomega) {
+				kcp := cp.get(g)
+				kcp.Spec.DeletionPolicy = controlplanev1alpha1.DeletionPolicyOrphan
+				g.Expect(k8sClient.Update(ctx, kcp)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			credentials := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cp.cluster), credentials)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, credentials)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cp.kcp)).To(Succeed())
+
+			cp.eventually(func(g Gomega) {
+				released(g, cp)
+			})
+			kluster, ok := fakeKKS.Kluster("orphaned")
+			Expect(ok).To(BeTrue())
+			Expect(kluster.Status.Phase).NotTo(Equal(models.KlusterPhaseTerminating))
+		})
+	})
+
+	Describe("credential errors", func() {
+		It("reports a missing credentials secret until it is created", func() {
+			cp := newControlPlane("missing-secret", nil)
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.CredentialsValidCondition, metav1.ConditionFalse, controlplanev1alpha1.CredentialsSecretNotFoundReason))
+			})
+			_, ok := fakeKKS.Kluster("missing-secret")
+			Expect(ok).To(BeFalse())
+
+			credentials := fakeKKS.CredentialsSecret(cp.cluster.Namespace, cp.cluster.Name)
+			Expect(k8sClient.Create(ctx, credentials)).To(Succeed())
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.CredentialsValidCondition, metav1.ConditionTrue, controlplanev1alpha1.CredentialsValidReason))
+				_, ok := fakeKKS.Kluster("missing-secret")
+				g.Expect(ok).To(BeTrue())
+			})
+		})
+
+		It("reports rejected credentials until they are fixed", func() {
+			credentials := fakeKKS.CredentialsSecret("", "")
+			credentials.Data[kubernikus.TokenKey] = []byte("wrong")
+			cp := newControlPlane("rejected", credentials)
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.CredentialsValidCondition, metav1.ConditionFalse, controlplanev1alpha1.AuthenticationFailedReason))
+			})
+
+			// the controller labels the secret for clusterctl move, update the latest version
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(credentials), credentials)).To(Succeed())
+				credentials.Data[kubernikus.TokenKey] = []byte(fakekubernikus.DefaultToken)
+				g.Expect(k8sClient.Update(ctx, credentials)).To(Succeed())
+			}).WithTimeout(eventuallyTimeout).Should(Succeed())
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.CredentialsValidCondition, metav1.ConditionTrue, controlplanev1alpha1.CredentialsValidReason))
+				_, ok := fakeKKS.Kluster("rejected")
+				g.Expect(ok).To(BeTrue())
+			})
+		})
+
+		It("reports malformed credentials", func() {
+			credentials := fakeKKS.CredentialsSecret("", "")
+			delete(credentials.Data, kubernikus.HostKey)
+			cp := newControlPlane("malformed", credentials)
+			cp.eventually(func(g Gomega) {
+				g.Expect(cp.get(g)).To(haveCondition(controlplanev1alpha1.CredentialsValidCondition, metav1.ConditionFalse, controlplanev1alpha1.InvalidCredentialsReason))
+			})
+		})
+	})
+})
@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+// fakeAPI is a KubernikusAPI keeping a single kluster in memory.
+type fakeAPI struct {
+	created    bool
+	phase      models.KlusterPhase
+	events     []*models.Event
+	terminated bool
+	// missing hides the kluster from status requests and termination, as if it had been removed in kubernikus.
+	missing bool
+}
+
+func (f *fakeAPI) EnsureControlPlane(_ context.Context, _ *controlplanev1alpha1.KubernikusControlPlane, _ logr.Logger) (*kubernikus.EnsureResult, error) {
+	if f.created {
+		return &kubernikus.EnsureResult{}, nil
+	}
+	f.created = true
+	f.phase = models.KlusterPhasePending
+	return &kubernikus.EnsureResult{Created: true}, nil
+}
+
+func (f *fakeAPI) GetKKSStatus(_ context.Context, _ *controlplanev1alpha1.KubernikusControlPlane, _ logr.Logger) (*controlplanev1alpha1.KubernikusControlPlaneStatus, error) {
+	if f.missing {
+		return &controlplanev1alpha1.KubernikusControlPlaneStatus{}, nil
+	}
+	return &controlplanev1alpha1.KubernikusControlPlaneStatus{
+		Ready: f.phase == models.KlusterPhaseRunning,
+		Phase: string(f.phase),
+	}, nil
+}
+
+func (f *fakeAPI) GetKKSEndpoint(context.Context, *controlplanev1alpha1.KubernikusControlPlane) (*capiv1beta1.APIEndpoint, error) {
+	return &capiv1beta1.APIEndpoint{Host: "test.kubernikus.example.com", Port: 443}, nil
+}
+
+func (f *fakeAPI) GetKKSKubeconfig(context.Context, *controlplanev1alpha1.KubernikusControlPlane, logr.Logger) (string, error) {
+	return "", nil
+}
+
+func (f *fakeAPI) GetKKSCa(context.Context, *controlplanev1alpha1.KubernikusControlPlane, logr.Logger) (v1.Secret, error) {
+	return v1.Secret{}, nil
+}
+
+func (f *fakeAPI) TerminateControlPlane(context.Context, *controlplanev1alpha1.KubernikusControlPlane, logr.Logger) (bool, error) {
+	if f.missing {
+		return true, nil
+	}
+	gone := f.terminated
+	f.terminated = true
+	return gone, nil
+}
+
+func (f *fakeAPI) GetKKSEvents(context.Context, *controlplanev1alpha1.KubernikusControlPlane) ([]*models.Event, error) {
+	return f.events, nil
+}
+
+func (f *fakeAPI) InvalidateToken() {}
+
+// newTestReconciler returns a reconciler for the control plane default/test, its Cluster and credentials secret,
+// backed by a fake client with objs in addition. The kubernikus api is api.
+func newTestReconciler(t *testing.T, api kubernikus.KubernikusAPI, objs ...client.Object) (*KubernikusControlPlaneReconciler, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = capiv1beta1.AddToScheme(scheme)
+	_ = controlplanev1alpha1.AddToScheme(scheme)
+
+	cluster := &capiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "cluster-uid"}}
+	kcp := &controlplanev1alpha1.KubernikusControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: capiv1beta1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+			}},
+		},
+		Spec: controlplanev1alpha1.KubernikusControlPlaneSpec{Version: "1.32.1"},
+	}
+	credentials := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Data: map[string][]byte{
+			kubernikus.HostKey:     []byte("kubernikus.example.com"),
+			kubernikus.AuthModeKey: []byte(kubernikus.AuthModeToken),
+			kubernikus.TokenKey:    []byte("token"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(append([]client.Object{cluster, kcp, credentials}, objs...)...).
+		WithStatusSubresource(&controlplanev1alpha1.KubernikusControlPlane{}).
+		Build()
+	return &KubernikusControlPlaneReconciler{
+		Client:   c,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+		NewKubernikusAPI: func(*kubernikus.Credentials) (kubernikus.KubernikusAPI, error) {
+			return api, nil
+		},
+	}, c
+}
+
+func TestReconcileWithFakeAPI(t *testing.T) {
+	api := &fakeAPI{events: []*models.Event{
+		{Type: "Normal", Reason: "Scheduled", LastTimestamp: "2026-01-01T10:00:00Z"},
+		{Type: "Warning", Reason: "QuotaExceeded", Message: "no floating ips left", LastTimestamp: "2026-01-01T10:05:00Z"},
+		{Type: "Warning", Reason: "Retrying", Message: "old", LastTimestamp: "2026-01-01T09:00:00Z"},
+	}}
+	r, c := newTestReconciler(t, api)
+	var creds []*kubernikus.Credentials
+	r.NewKubernikusAPI = func(c *kubernikus.Credentials) (kubernikus.KubernikusAPI, error) {
+		creds = append(creds, c)
+		return api, nil
+	}
+	r.RequeueIntervals = RequeueIntervals{Provisioning: 7 * time.Second}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test", Namespace: "default"}}
+
+	result, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != 7*time.Second {
+		t.Errorf("expected a requeue after the provisioning interval, got %+v", result)
+	}
+	if len(creds) != 1 || creds[0].Host != "kubernikus.example.com" || creds[0].Token != "token" {
+		t.Errorf("expected the factory to be called with the credentials of the secret, got %+v", creds)
+	}
+	if !api.created {
+		t.Error("expected the kluster to be created")
+	}
+
+	var got controlplanev1alpha1.KubernikusControlPlane
+	if err := c.Get(context.Background(), req.NamespacedName, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !controllerutil.ContainsFinalizer(&got, controlplanev1alpha1.KubernikusControlPlaneFinalizer) {
+		t.Error("expected the finalizer to be added")
+	}
+	if got.Status.Phase != string(models.KlusterPhasePending) {
+		t.Errorf("expected phase Pending, got %q", got.Status.Phase)
+	}
+	if paused := meta.FindStatusCondition(got.Status.Conditions, controlplanev1alpha1.PausedCondition); paused == nil || paused.Status != metav1.ConditionFalse {
+		t.Errorf("expected the Paused condition to be false, got %+v", paused)
+	}
+	ready := meta.FindStatusCondition(got.Status.Conditions, controlplanev1alpha1.ControlPlaneReadyCondition)
+	if ready == nil || ready.Status != metav1.ConditionFalse || !strings.Contains(ready.Message, "QuotaExceeded: no floating ips left") {
+		t.Errorf("expected the latest warning in the ControlPlaneReady condition, got %+v", ready)
+	}
+
+	if err := c.Delete(context.Background(), &got); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := r.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !api.terminated {
+		t.Error("expected the kluster to be terminated")
+	}
+	if err := c.Get(context.Background(), req.NamespacedName, &got); err == nil {
+		t.Errorf("expected the control plane to be gone, finalizers %v", got.Finalizers)
+	}
+}
+
+func TestReconcileKlusterNotFound(t *testing.T) {
+	api := &fakeAPI{}
+	r, c := newTestReconciler(t, api)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test", Namespace: "default"}}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api.missing = true
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got controlplanev1alpha1.KubernikusControlPlane
+	if err := c.Get(context.Background(), req.NamespacedName, &got); err != nil {
+		t.Fatal(err)
+	}
+	provisioned := meta.FindStatusCondition(got.Status.Conditions, controlplanev1alpha1.KlusterProvisionedCondition)
+	if provisioned == nil || provisioned.Reason != controlplanev1alpha1.KlusterNotFoundReason {
+		t.Errorf("expected the KlusterProvisioned condition to report the missing kluster, got %+v", provisioned)
+	}
+
+	if err := c.Delete(context.Background(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(context.Background(), req.NamespacedName, &got); err == nil {
+		t.Errorf("expected the control plane to be released, finalizers %v", got.Finalizers)
+	}
+	notFound := 0
+	for len(recorder.Events) > 0 {
+		if strings.Contains(<-recorder.Events, controlplanev1alpha1.KlusterNotFoundReason) {
+			notFound++
+		}
+	}
+	if notFound != 2 {
+		t.Errorf("expected KlusterNotFound events for the status and the termination, got %d", notFound)
+	}
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	"github.com/sapcc/kubernikus/pkg/api/models"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+// RequeueIntervals configures when a control plane is reconciled again without a change to it.
+// Zero fields fall back to DefaultRequeueIntervals.
+type RequeueIntervals struct {
+	// Provisioning is used while the kluster is Pending or Creating.
+	Provisioning time.Duration
+	// Upgrading is used while the kluster is Upgrading.
+	Upgrading time.Duration
+	// Terminating is used while kubernikus terminates the kluster of a deleted control plane.
+	Terminating time.Duration
+	// Running is used once the kluster is running, and to retry terminal failures.
+	Running time.Duration
+	// KubeconfigRotation is how long before its client certificate expires the kubeconfig is rotated.
+	// The control plane is reconciled in time for the rotation, even if Running is longer.
+	KubeconfigRotation time.Duration
+}
+
+// DefaultRequeueIntervals are the intervals used unless configured otherwise.
+var DefaultRequeueIntervals = RequeueIntervals{
+	Provisioning:       15 * time.Second,
+	Upgrading:          30 * time.Second,
+	Terminating:        15 * time.Second,
+	Running:            10 * time.Minute,
+	KubeconfigRotation: 30 * time.Minute,
+}
+
+func (i RequeueIntervals) withDefaults() RequeueIntervals {
+	if i.Provisioning <= 0 {
+		i.Provisioning = DefaultRequeueIntervals.Provisioning
+	}
+	if i.Upgrading <= 0 {
+		i.Upgrading = DefaultRequeueIntervals.Upgrading
+	}
+	if i.Terminating <= 0 {
+		i.Terminating = DefaultRequeueIntervals.Terminating
+	}
+	if i.Running <= 0 {
+		i.Running = DefaultRequeueIntervals.Running
+	}
+	if i.KubeconfigRotation <= 0 {
+		i.KubeconfigRotation = DefaultRequeueIntervals.KubeconfigRotation
+	}
+	return i
+}
+
+// forPhase returns the interval for a kluster in phase. Klusters without a phase
+// have just been created and are polled like provisioning ones.
+func (i RequeueIntervals) forPhase(phase models.KlusterPhase) time.Duration {
+	switch phase {
+	case models.KlusterPhaseRunning:
+		return i.Running
+	case models.KlusterPhaseUpgrading:
+		return i.Upgrading
+	case models.KlusterPhaseTerminating:
+		return i.Terminating
+	default:
+		return i.Provisioning
+	}
+}
+
+// untilKubeconfigRotation returns how long the running interval may be, so the kubeconfig in sec is rotated
+// before its client certificate expires.
+// A kubeconfig still due for rotation has just been refetched, kubernikus issued it with a validity shorter
+// than the rotation lead. Refetching it again right away does not help, so it is refetched after half of
+// its remaining validity, or after the running interval once it expired.
+func (i RequeueIntervals) untilKubeconfigRotation(sec *v1.Secret, now time.Time) time.Duration {
+	expiry, err := kubeconfigExpiry(sec)
+	if err != nil {
+		return i.Running
+	}
+	if wait := expiry.Add(-i.KubeconfigRotation).Sub(now); wait > 0 {
+		return min(i.Running, max(wait, i.Provisioning))
+	}
+	remaining := expiry.Sub(now)
+	if remaining <= 0 {
+		return i.Running
+	}
+	return min(i.Running, max(remaining/2, i.Provisioning))
+}
+
+// kubeconfigExpiry returns when the client certificate of the kubeconfig in sec expires.
+func kubeconfigExpiry(sec *v1.Secret) (time.Time, error) {
+	config, err := clientcmd.Load(sec.Data[secret.KubeconfigDataName])
+	if err != nil {
+		return time.Time{}, err
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return time.Time{}, errors.New("kubeconfig has no current context")
+	}
+	authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return time.Time{}, errors.New("kubeconfig has no user for the current context")
+	}
+	block, _ := pem.Decode(authInfo.ClientCertificateData)
+	if block == nil {
+		return time.Time{}, errors.New("kubeconfig has no client certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
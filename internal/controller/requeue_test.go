@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sapcc/kubernikus/pkg/api/models"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func TestRequeueIntervalsForPhase(t *testing.T) {
+	intervals := RequeueIntervals{Upgrading: time.Minute}.withDefaults()
+	for phase, expected := range map[models.KlusterPhase]time.Duration{
+		"":                             DefaultRequeueIntervals.Provisioning,
+		models.KlusterPhasePending:     DefaultRequeueIntervals.Provisioning,
+		models.KlusterPhaseCreating:    DefaultRequeueIntervals.Provisioning,
+		models.KlusterPhaseUpgrading:   time.Minute,
+		models.KlusterPhaseTerminating: DefaultRequeueIntervals.Terminating,
+		models.KlusterPhaseRunning:     DefaultRequeueIntervals.Running,
+	} {
+		if got := intervals.forPhase(phase); got != expected {
+			t.Errorf("phase %q: expected %v, got %v", phase, expected, got)
+		}
+	}
+}
+
+func TestRequeueIntervalsUntilKubeconfigRotation(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	intervals := RequeueIntervals{
+		Provisioning:       15 * time.Second,
+		Running:            10 * time.Minute,
+		KubeconfigRotation: 30 * time.Minute,
+	}.withDefaults()
+
+	for name, tc := range map[string]struct {
+		sec      *v1.Secret
+		expected time.Duration
+	}{
+		"expiry far ahead":       {kubeconfigSecret(t, now.Add(24*time.Hour)), 10 * time.Minute},
+		"rotation due soon":      {kubeconfigSecret(t, now.Add(35*time.Minute)), 5 * time.Minute},
+		"short lived":            {kubeconfigSecret(t, now.Add(10*time.Minute)), 5 * time.Minute},
+		"about to expire":        {kubeconfigSecret(t, now.Add(20*time.Second)), 15 * time.Second},
+		"expired":                {kubeconfigSecret(t, now.Add(-time.Minute)), 10 * time.Minute},
+		"unparseable kubeconfig": {&v1.Secret{Data: map[string][]byte{secret.KubeconfigDataName: []byte("{")}}, 10 * time.Minute},
+	} {
+		if got := intervals.untilKubeconfigRotation(tc.sec, now); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", name, tc.expected, got)
+		}
+	}
+}
+
+// kubeconfigSecret returns a kubeconfig secret with a client certificate expiring at notAfter.
+func kubeconfigSecret(t *testing.T, notAfter time.Time) *v1.Secret {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := clientcmdapi.NewConfig()
+	config.Clusters["test"] = &clientcmdapi.Cluster{Server: "https://test.example.com"}
+	config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+	config.Contexts["test"] = &clientcmdapi.Context{Cluster: "test", AuthInfo: "admin"}
+	config.CurrentContext = "test"
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &v1.Secret{Data: map[string][]byte{secret.KubeconfigDataName: data}}
+}
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+const (
+	// credentialsSecretField indexes control planes by the name of the credentials secret in their namespace.
+	// Control planes using an identity are indexed by identityField instead.
+	credentialsSecretField = ".spec.credentialsSecret"
+	// identityField indexes control planes by the name of their KubernikusIdentity.
+	identityField = ".spec.identityRef.name"
+	// identitySecretField indexes KubernikusIdentities by the name of their credentials secret.
+	identitySecretField = ".spec.secretRef"
+)
+
+// CacheOptions restricts the secrets cached for the watches of the controller to the ones labelled
+// with SecretLabel, instead of every secret of the cluster.
+func CacheOptions() cache.Options {
+	labeled, _ := labels.NewRequirement(controlplanev1alpha1.SecretLabel, selection.Exists, nil)
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&v1.Secret{}: {Label: labels.NewSelector().Add(*labeled)},
+		},
+	}
+}
+
+// ClientOptions reads secrets from the api server, as credentials secrets are only cached once
+// the controller labelled them.
+func ClientOptions() client.Options {
+	return client.Options{
+		Cache: &client.CacheOptions{DisableFor: []client.Object{&v1.Secret{}}},
+	}
+}
+
+// setupIndexes registers the field indexes used to map watched objects to control planes.
+func (r *KubernikusControlPlaneReconciler) setupIndexes(ctx context.Context, mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	err := indexer.IndexField(ctx, &controlplanev1alpha1.KubernikusControlPlane{}, credentialsSecretField, indexCredentialsSecret)
+	if err != nil {
+		return err
+	}
+	err = indexer.IndexField(ctx, &controlplanev1alpha1.KubernikusControlPlane{}, identityField, indexIdentity)
+	if err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &controlplanev1alpha1.KubernikusIdentity{}, identitySecretField, indexIdentitySecret)
+}
+
+// indexCredentialsSecret returns the name of the credentials secret of a control plane,
+// like credentialsSecretKey does for control planes without an identity.
+func indexCredentialsSecret(obj client.Object) []string {
+	kcp, ok := obj.(*controlplanev1alpha1.KubernikusControlPlane)
+	if !ok || kcp.Spec.IdentityRef != nil {
+		return nil
+	}
+	if kcp.Spec.CredentialsRef != nil && kcp.Spec.CredentialsRef.Name != "" {
+		return []string{kcp.Spec.CredentialsRef.Name}
+	}
+	if name := ownerClusterName(kcp); name != "" {
+		return []string{name}
+	}
+	return nil
+}
+
+func indexIdentity(obj client.Object) []string {
+	kcp, ok := obj.(*controlplanev1alpha1.KubernikusControlPlane)
+	if !ok || kcp.Spec.IdentityRef == nil {
+		return nil
+	}
+	return []string{kcp.Spec.IdentityRef.Name}
+}
+
+func indexIdentitySecret(obj client.Object) []string {
+	identity, ok := obj.(*controlplanev1alpha1.KubernikusIdentity)
+	if !ok || identity.Spec.SecretRef == "" {
+		return nil
+	}
+	return []string{identity.Spec.SecretRef}
+}
+
+// clusterToKubernikusControlPlane maps a Cluster to the KubernikusControlPlane it references as control plane.
+func (r *KubernikusControlPlaneReconciler) clusterToKubernikusControlPlane(_ context.Context, obj client.Object) []ctrl.Request {
+	cluster, ok := obj.(*capiv1beta1.Cluster)
+	if !ok || cluster.Spec.ControlPlaneRef == nil {
+		return nil
+	}
+	ref := cluster.Spec.ControlPlaneRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil || gv.Group != controlplanev1alpha1.GroupVersion.Group || ref.Kind != "KubernikusControlPlane" {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name}}}
+}
+
+// secretToKubernikusControlPlanes maps a secret to the control planes using it as credentials,
+// either directly or through a KubernikusIdentity.
+func (r *KubernikusControlPlaneReconciler) secretToKubernikusControlPlanes(ctx context.Context, obj client.Object) []ctrl.Request {
+	logger := log.FromContext(ctx).WithValues("secret", client.ObjectKeyFromObject(obj))
+
+	var kcps controlplanev1alpha1.KubernikusControlPlaneList
+	err := r.List(ctx, &kcps, client.InNamespace(obj.GetNamespace()), client.MatchingFields{credentialsSecretField: obj.GetName()})
+	if err != nil {
+		logger.Error(err, "Failed to list KubernikusControlPlanes using the secret")
+		return nil
+	}
+	requests := r.requestsFor(kcps.Items)
+
+	if r.IdentityNamespace == "" || obj.GetNamespace() != r.IdentityNamespace {
+		return requests
+	}
+	var identities controlplanev1alpha1.KubernikusIdentityList
+	err = r.List(ctx, &identities, client.MatchingFields{identitySecretField: obj.GetName()})
+	if err != nil {
+		logger.Error(err, "Failed to list KubernikusIdentities using the secret")
+		return requests
+	}
+	for i := range identities.Items {
+		requests = append(requests, r.identityToKubernikusControlPlanes(ctx, &identities.Items[i])...)
+	}
+	return requests
+}
+
+// identityToKubernikusControlPlanes maps a KubernikusIdentity to the control planes referencing it.
+func (r *KubernikusControlPlaneReconciler) identityToKubernikusControlPlanes(ctx context.Context, obj client.Object) []ctrl.Request {
+	var kcps controlplanev1alpha1.KubernikusControlPlaneList
+	err := r.List(ctx, &kcps, client.MatchingFields{identityField: obj.GetName()})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list KubernikusControlPlanes using the identity", "identity", obj.GetName())
+		return nil
+	}
+	return r.requestsFor(kcps.Items)
+}
+
+// requestsFor returns the reconcile requests of the control planes selected by the watch filter.
+func (r *KubernikusControlPlaneReconciler) requestsFor(kcps []controlplanev1alpha1.KubernikusControlPlane) []ctrl.Request {
+	requests := make([]ctrl.Request, 0, len(kcps))
+	for _, kcp := range kcps {
+		if r.WatchFilterValue != "" && kcp.Labels[capiv1beta1.WatchLabel] != r.WatchFilterValue {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&kcp)})
+	}
+	return requests
+}
+
+// statusUnchanged drops updates of a control plane that only change its status, which the reconciler writes itself.
+// Unlike predicate.GenerationChangedPredicate it passes changes of the metadata, like new owner references.
+func statusUnchanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldKCP, ok := e.ObjectOld.(*controlplanev1alpha1.KubernikusControlPlane)
+			if !ok {
+				return true
+			}
+			newKCP, ok := e.ObjectNew.(*controlplanev1alpha1.KubernikusControlPlane)
+			if !ok {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(withoutStatus(oldKCP), withoutStatus(newKCP))
+		},
+	}
+}
+
+func withoutStatus(kcp *controlplanev1alpha1.KubernikusControlPlane) *controlplanev1alpha1.KubernikusControlPlane {
+	kcp = kcp.DeepCopy()
+	kcp.Status = controlplanev1alpha1.KubernikusControlPlaneStatus{}
+	kcp.ResourceVersion = ""
+	kcp.ManagedFields = nil
+	return kcp
+}
+
+// secretDataChanged drops updates of secrets that do not change their data.
+func secretDataChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, ok := e.ObjectOld.(*v1.Secret)
+			if !ok {
+				return true
+			}
+			newSecret, ok := e.ObjectNew.(*v1.Secret)
+			if !ok {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(oldSecret.Data, newSecret.Data)
+		},
+	}
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+func TestSecretToKubernikusControlPlanes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = controlplanev1alpha1.AddToScheme(scheme)
+
+	kcp := func(namespace, name string, mutate func(*controlplanev1alpha1.KubernikusControlPlane)) *controlplanev1alpha1.KubernikusControlPlane {
+		kcp := &controlplanev1alpha1.KubernikusControlPlane{ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: capiv1beta1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       name,
+			}},
+		}}
+		if mutate != nil {
+			mutate(kcp)
+		}
+		return kcp
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&controlplanev1alpha1.KubernikusControlPlane{}, credentialsSecretField, indexCredentialsSecret).
+		WithIndex(&controlplanev1alpha1.KubernikusControlPlane{}, identityField, indexIdentity).
+		WithIndex(&controlplanev1alpha1.KubernikusIdentity{}, identitySecretField, indexIdentitySecret).
+		WithObjects(
+			kcp("team-a", "default-secret", nil),
+			kcp("team-a", "shared-1", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.CredentialsRef = &v1.LocalObjectReference{Name: "shared"}
+			}),
+			kcp("team-b", "shared-2", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.CredentialsRef = &v1.LocalObjectReference{Name: "shared"}
+			}),
+			kcp("team-a", "identity", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.IdentityRef = &controlplanev1alpha1.KubernikusIdentityReference{Name: "production"}
+			}),
+			kcp("team-b", "filtered", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Labels = map[string]string{capiv1beta1.WatchLabel: "other"}
+				kcp.Spec.IdentityRef = &controlplanev1alpha1.KubernikusIdentityReference{Name: "production"}
+			}),
+			&controlplanev1alpha1.KubernikusIdentity{
+				ObjectMeta: metav1.ObjectMeta{Name: "production"},
+				Spec:       controlplanev1alpha1.KubernikusIdentitySpec{SecretRef: "production-credentials"},
+			},
+		).
+		Build()
+	r := &KubernikusControlPlaneReconciler{Client: c, IdentityNamespace: "kubernikus-system"}
+
+	for name, tc := range map[string]struct {
+		secret           client.ObjectKey
+		watchFilterValue string
+		expected         []string
+	}{
+		"secret named after the cluster": {
+			secret:   client.ObjectKey{Namespace: "team-a", Name: "default-secret"},
+			expected: []string{"team-a/default-secret"},
+		},
+		"credentialsRef is namespaced": {
+			secret:   client.ObjectKey{Namespace: "team-a", Name: "shared"},
+			expected: []string{"team-a/shared-1"},
+		},
+		"identity secret": {
+			secret:   client.ObjectKey{Namespace: "kubernikus-system", Name: "production-credentials"},
+			expected: []string{"team-a/identity", "team-b/filtered"},
+		},
+		"identity secret with watch filter": {
+			secret:           client.ObjectKey{Namespace: "kubernikus-system", Name: "production-credentials"},
+			watchFilterValue: "other",
+			expected:         []string{"team-b/filtered"},
+		},
+		"identity secret name in another namespace": {
+			secret: client.ObjectKey{Namespace: "team-a", Name: "production-credentials"},
+		},
+		"unrelated secret": {
+			secret: client.ObjectKey{Namespace: "team-a", Name: "default-secret-kubeconfig"},
+		},
+	} {
+		r.WatchFilterValue = tc.watchFilterValue
+		sec := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: tc.secret.Namespace, Name: tc.secret.Name}}
+		got := requestNames(r.secretToKubernikusControlPlanes(context.Background(), sec))
+		if !slices.Equal(got, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", name, tc.expected, got)
+		}
+	}
+}
+
+func TestClusterToKubernikusControlPlane(t *testing.T) {
+	r := &KubernikusControlPlaneReconciler{}
+	cluster := func(ref *v1.ObjectReference) *capiv1beta1.Cluster {
+		return &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "test"},
+			Spec:       capiv1beta1.ClusterSpec{ControlPlaneRef: ref},
+		}
+	}
+
+	got := requestNames(r.clusterToKubernikusControlPlane(context.Background(), cluster(&v1.ObjectReference{
+		APIVersion: controlplanev1alpha1.GroupVersion.String(),
+		Kind:       "KubernikusControlPlane",
+		Name:       "test-cp",
+	})))
+	if !slices.Equal(got, []string{"team-a/test-cp"}) {
+		t.Errorf("expected the referenced control plane, got %v", got)
+	}
+
+	for _, ref := range []*v1.ObjectReference{
+		nil,
+		{APIVersion: "controlplane.cluster.x-k8s.io/v1beta1", Kind: "KubeadmControlPlane", Name: "test"},
+	} {
+		if got := r.clusterToKubernikusControlPlane(context.Background(), cluster(ref)); len(got) != 0 {
+			t.Errorf("expected no requests for control plane %v, got %v", ref, got)
+		}
+	}
+}
+
+func TestWatchPredicates(t *testing.T) {
+	kcp := &controlplanev1alpha1.KubernikusControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", ResourceVersion: "1"},
+		Spec:       controlplanev1alpha1.KubernikusControlPlaneSpec{Version: "v1.32.1"},
+	}
+	statusUpdate := kcp.DeepCopy()
+	statusUpdate.ResourceVersion = "2"
+	statusUpdate.Status.Ready = true
+	if statusUnchanged().Update(event.UpdateEvent{ObjectOld: kcp, ObjectNew: statusUpdate}) {
+		t.Error("expected status updates to be dropped")
+	}
+	adopted := statusUpdate.DeepCopy()
+	adopted.OwnerReferences = []metav1.OwnerReference{{Kind: "Cluster", Name: "test"}}
+	if !statusUnchanged().Update(event.UpdateEvent{ObjectOld: statusUpdate, ObjectNew: adopted}) {
+		t.Error("expected new owner references to pass")
+	}
+
+	sec := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test", ResourceVersion: "1"}, Data: map[string][]byte{"token": []byte("a")}}
+	relabeled := sec.DeepCopy()
+	relabeled.Labels = map[string]string{"foo": "bar"}
+	if secretDataChanged().Update(event.UpdateEvent{ObjectOld: sec, ObjectNew: relabeled}) {
+		t.Error("expected secret updates without data changes to be dropped")
+	}
+	rotated := sec.DeepCopy()
+	rotated.Data["token"] = []byte("b")
+	if !secretDataChanged().Update(event.UpdateEvent{ObjectOld: sec, ObjectNew: rotated}) {
+		t.Error("expected rotated secrets to pass")
+	}
+}
+
+func requestNames(requests []ctrl.Request) []string {
+	names := make([]string, 0, len(requests))
+	for _, req := range requests {
+		names = append(names, req.String())
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package fakekubernikus
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// loginForm is the page the fake dex shows for the username and password form.
+const loginForm = `<html><body><form method="post"><input name="login"><input name="password" type="password"></form></body></html>`
+
+// login starts the dex login flow by redirecting to the password form of the connector.
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[Login]++
+	injected := s.injectedError(Login)
+	s.mu.Unlock()
+	if injected != nil {
+		http.Error(w, injected.Message, injected.Code)
+		return
+	}
+	q := url.Values{}
+	q.Set("connector_id", r.URL.Query().Get("connector_id"))
+	http.Redirect(w, r, "/auth/login/local?"+q.Encode(), http.StatusFound)
+}
+
+// loginForm shows the password form and redirects to the token once the password is correct.
+// Like dex, wrong passwords show the form again instead of failing.
+func (s *Server) loginForm(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		username, password := r.PostFormValue("login"), r.PostFormValue("password")
+		if expected, ok := s.opts.Users[username]; ok && expected == password {
+			code, err := randomString()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			s.mu.Lock()
+			s.codes[code] = username
+			s.mu.Unlock()
+			http.Redirect(w, r, "/auth/login/token?code="+code, http.StatusSeeOther)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write([]byte(loginForm))
+}
+
+// loginToken exchanges the code of a successful login for an id token.
+func (s *Server) loginToken(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("code")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	username, ok := s.codes[code]
+	if !ok {
+		http.Error(w, "invalid code", http.StatusBadRequest)
+		return
+	}
+	delete(s.codes, code)
+
+	expiresAt := s.opts.Now().Add(s.opts.TokenLifetime)
+	nonce, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// an unsigned JWT, clients only inspect its expiry
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	claims := base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil,
+		`{"iss":"fake-kubernikus","sub":%q,"exp":%d,"nonce":%q}`, username, expiresAt.Unix(), nonce))
+	token := header + "." + claims + ".c2lnbmF0dXJl"
+	s.tokens[token] = expiresAt
+	writeJSON(w, http.StatusOK, map[string]string{"idToken": token, "type": "Bearer"})
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
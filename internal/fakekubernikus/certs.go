@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package fakekubernikus
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// caValidity is the validity of the certificate authorities of the klusters.
+const caValidity = 10 * 365 * 24 * time.Hour
+
+// newCA creates the certificate authority of a kluster.
+func newCA(name string, now time.Time) (*x509.Certificate, *rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name, OrganizationalUnit: []string{"fake-kubernikus"}},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// newKubeconfig creates an admin kubeconfig for the kluster with a freshly issued client certificate.
+func newKubeconfig(k *kluster, now time.Time, validity time.Duration) ([]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "kubernikus:admin", Organization: []string{"system:masters"}},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, k.caCert, &key.PublicKey, k.caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[k.Name] = &clientcmdapi.Cluster{
+		Server:                   k.Status.Apiserver,
+		CertificateAuthorityData: encodeCertificate(k.caCert.Raw),
+	}
+	config.AuthInfos[k.Name] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: encodeCertificate(der),
+		ClientKeyData:         encodeKey(key),
+	}
+	config.Contexts[k.Name] = &clientcmdapi.Context{Cluster: k.Name, AuthInfo: k.Name}
+	config.CurrentContext = k.Name
+	return clientcmd.Write(*config)
+}
+
+// newKubeadmSecret serializes the certificate authority of the kluster like kubernikus does,
+// as a secret with base64 encoded string data.
+func newKubeadmSecret(k *kluster) ([]byte, error) {
+	secret := corev1.Secret{
+		StringData: map[string]string{
+			"tls.crt": base64.StdEncoding.EncodeToString(encodeCertificate(k.caCert.Raw)),
+			"tls.key": base64.StdEncoding.EncodeToString(encodeKey(k.caKey)),
+		},
+	}
+	secret.Name = k.Name + "-kubeadm"
+	return yaml.Marshal(secret)
+}
+
+func encodeCertificate(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func encodeKey(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// ServingCertificate creates a self-signed serving certificate for hosts, which are dns names or ip addresses.
+// It returns the certificate and its PEM encoding, which clients use as CA bundle.
+func ServingCertificate(hosts []string, now time.Time) (tls.Certificate, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "fake-kubernikus"},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	certPEM := encodeCertificate(der)
+	cert, err := tls.X509KeyPair(certPEM, encodeKey(key))
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	return cert, certPEM, nil
+}
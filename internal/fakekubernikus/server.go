@@ -0,0 +1,487 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fakekubernikus implements an in-memory stand-in for the parts of the kubernikus api
+// used by the control plane provider. It is used by tests, through NewTestServer, and for local
+// development, through the fake-kubernikus binary.
+package fakekubernikus
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sapcc/kubernikus/pkg/api/models"
+)
+
+// Operations of the kubernikus api served by the fake, named like the go-swagger operations.
+// They are used to inject errors and to count requests.
+const (
+	ListClusters            = "ListClusters"
+	ShowCluster             = "ShowCluster"
+	CreateCluster           = "CreateCluster"
+	UpdateCluster           = "UpdateCluster"
+	TerminateCluster        = "TerminateCluster"
+	GetClusterCredentials   = "GetClusterCredentials"
+	GetClusterKubeadmSecret = "GetClusterKubeadmSecret"
+	GetClusterEvents        = "GetClusterEvents"
+	Login                   = "Login"
+)
+
+const (
+	// DefaultToken is accepted as bearer token unless Options.Tokens is set.
+	DefaultToken = "fake-kubernikus-token"
+	// DefaultUsername and DefaultPassword log into the fake dex unless Options.Users is set.
+	DefaultUsername = "fake"
+	DefaultPassword = "fake"
+	// DefaultVersion is the version of klusters created without one.
+	DefaultVersion = "1.32.1"
+	// DefaultDomain is the domain of the apiservers of the klusters.
+	DefaultDomain = "kubernikus.fake"
+)
+
+// klusterNamePattern and maxKlusterNameLength match the validation of the kubernikus api.
+var klusterNamePattern = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+
+const maxKlusterNameLength = 20
+
+// Options configures a Server. The zero value is usable.
+type Options struct {
+	// PhaseDuration is how long klusters stay in the Pending, Creating, Upgrading and Terminating phases.
+	// With the zero value klusters are running as soon as they are read after their creation.
+	PhaseDuration time.Duration
+	// CertificateValidity is the validity of the client certificates in the kubeconfigs, 24 hours by default.
+	CertificateValidity time.Duration
+	// TokenLifetime is the lifetime of the tokens issued by the fake dex, one hour by default.
+	TokenLifetime time.Duration
+	// Tokens are accepted as bearer or keystone tokens, DefaultToken if nil.
+	Tokens []string
+	// Users maps the usernames accepted by the fake dex to their passwords,
+	// DefaultUsername with DefaultPassword if nil.
+	Users map[string]string
+	// Domain is the domain of the apiservers of the klusters, DefaultDomain if empty.
+	Domain string
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+// Error is an error returned by the fake instead of handling a request.
+type Error struct {
+	// Code is the http status code of the response.
+	Code int
+	// Message is the message of the kubernikus error payload.
+	Message string
+	// Times is how often the error is returned, zero returns it until the errors are cleared.
+	Times int
+}
+
+// Server is the fake kubernikus api. It is an http.Handler and keeps its klusters in memory.
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu       sync.Mutex
+	klusters map[string]*kluster
+	tokens   map[string]time.Time
+	errors   map[string]*Error
+	requests map[string]int
+	codes    map[string]string
+}
+
+type kluster struct {
+	models.Kluster
+	phaseSince time.Time
+	caCert     *x509.Certificate
+	caKey      *rsa.PrivateKey
+	events     []*models.Event
+}
+
+// New creates a Server without klusters.
+func New(opts Options) *Server {
+	if opts.CertificateValidity <= 0 {
+		opts.CertificateValidity = 24 * time.Hour
+	}
+	if opts.TokenLifetime <= 0 {
+		opts.TokenLifetime = time.Hour
+	}
+	if opts.Tokens == nil {
+		opts.Tokens = []string{DefaultToken}
+	}
+	if opts.Users == nil {
+		opts.Users = map[string]string{DefaultUsername: DefaultPassword}
+	}
+	if opts.Domain == "" {
+		opts.Domain = DefaultDomain
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	s := &Server{
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		klusters: map[string]*kluster{},
+		tokens:   map[string]time.Time{},
+		errors:   map[string]*Error{},
+		requests: map[string]int{},
+		codes:    map[string]string{},
+	}
+	s.mux.HandleFunc("GET /api/v1/clusters", s.api(ListClusters, s.listClusters))
+	s.mux.HandleFunc("POST /api/v1/clusters", s.api(CreateCluster, s.createCluster))
+	s.mux.HandleFunc("GET /api/v1/clusters/{name}", s.api(ShowCluster, s.showCluster))
+	s.mux.HandleFunc("PUT /api/v1/clusters/{name}", s.api(UpdateCluster, s.updateCluster))
+	s.mux.HandleFunc("DELETE /api/v1/clusters/{name}", s.api(TerminateCluster, s.terminateCluster))
+	s.mux.HandleFunc("GET /api/v1/clusters/{name}/credentials", s.api(GetClusterCredentials, s.getCredentials))
+	s.mux.HandleFunc("GET /api/v1/clusters/{name}/kubeadmsecret", s.api(GetClusterKubeadmSecret, s.getKubeadmSecret))
+	s.mux.HandleFunc("GET /api/v1/clusters/{name}/events", s.api(GetClusterEvents, s.getEvents))
+	s.mux.HandleFunc("GET /auth/login", s.login)
+	s.mux.HandleFunc("/auth/login/local", s.loginForm)
+	s.mux.HandleFunc("GET /auth/login/token", s.loginToken)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// InjectError makes the fake fail requests of the operation with err.
+func (s *Server) InjectError(operation string, err Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[operation] = &err
+}
+
+// ClearErrors removes all injected errors.
+func (s *Server) ClearErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = map[string]*Error{}
+}
+
+// Requests returns how often the operation has been requested, including failed requests.
+func (s *Server) Requests(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[operation]
+}
+
+// Kluster returns a copy of the kluster, or false if it does not exist.
+func (s *Server) Kluster(name string) (models.Kluster, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(name)
+	if !ok {
+		return models.Kluster{}, false
+	}
+	return k.Kluster, true
+}
+
+// SetPhase moves the kluster into phase, independent of the PhaseDuration.
+// Moving a kluster into the Running phase completes a pending upgrade.
+func (s *Server) SetPhase(name string, phase models.KlusterPhase) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(name)
+	if !ok {
+		return false
+	}
+	s.setPhase(k, phase)
+	return true
+}
+
+// AddEvent records an event for the kluster.
+func (s *Server) AddEvent(name, eventType, reason, message string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(name)
+	if !ok {
+		return false
+	}
+	s.addEvent(k, eventType, reason, message)
+	return true
+}
+
+// api wraps the handler of an operation with request counting, error injection and authentication.
+func (s *Server) api(operation string, handler func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[operation]++
+		injected := s.injectedError(operation)
+		authenticated := s.authenticated(r)
+		s.mu.Unlock()
+
+		switch {
+		case injected != nil:
+			writeError(w, injected.Code, injected.Message)
+		case !authenticated:
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+		default:
+			handler(w, r)
+		}
+	}
+}
+
+// injectedError returns the error injected for the operation and counts it down.
+func (s *Server) injectedError(operation string) *Error {
+	injected, ok := s.errors[operation]
+	if !ok {
+		return nil
+	}
+	if injected.Times > 0 {
+		injected.Times--
+		if injected.Times == 0 {
+			delete(s.errors, operation)
+		}
+	}
+	return injected
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	token := r.Header.Get("X-Auth-Token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	if token == "" {
+		return false
+	}
+	if slices.Contains(s.opts.Tokens, token) {
+		return true
+	}
+	expiresAt, ok := s.tokens[token]
+	return ok && s.opts.Now().Before(expiresAt)
+}
+
+func (s *Server) listClusters(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.klusters))
+	for name := range s.klusters {
+		if _, ok := s.get(name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	ret := make([]*models.Kluster, 0, len(names))
+	for _, name := range names {
+		k := s.klusters[name].Kluster
+		ret = append(ret, &k)
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
+
+func (s *Server) showCluster(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, k.Kluster)
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *http.Request) {
+	var body models.Kluster
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(body.Name) > maxKlusterNameLength || !klusterNamePattern.MatchString(body.Name) {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("name in body should match '%s' and be at most %d characters long", klusterNamePattern, maxKlusterNameLength))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(body.Name); ok {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Cluster with name %s already exists", body.Name))
+		return
+	}
+	caCert, caKey, err := newCA(body.Name, s.opts.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if body.Spec.Version == "" {
+		body.Spec.Version = DefaultVersion
+	}
+	body.Spec.Name = body.Name
+	body.Status = models.KlusterStatus{
+		Apiserver: fmt.Sprintf("https://%s.%s", body.Name, s.opts.Domain),
+		Version:   "fake",
+	}
+	k := &kluster{Kluster: body, caCert: caCert, caKey: caKey}
+	s.klusters[body.Name] = k
+	s.setPhase(k, models.KlusterPhasePending)
+	writeJSON(w, http.StatusCreated, k.Kluster)
+}
+
+func (s *Server) updateCluster(w http.ResponseWriter, r *http.Request) {
+	var body models.Kluster
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if k.Status.Phase == models.KlusterPhaseTerminating {
+		writeError(w, http.StatusConflict, "Cluster is terminating")
+		return
+	}
+	upgrade := body.Spec.Version != "" && body.Spec.Version != k.Spec.Version
+	// like kubernikus, versions are only changed on running klusters
+	if upgrade && k.Status.Phase != models.KlusterPhaseRunning {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Version can be changed in state %s only", models.KlusterPhaseRunning))
+		return
+	}
+	if upgrade {
+		k.Spec.Version = body.Spec.Version
+	}
+	k.Spec.Dashboard = body.Spec.Dashboard
+	k.Spec.Dex = body.Spec.Dex
+	k.Spec.Audit = body.Spec.Audit
+	k.Spec.AuthenticationConfiguration = body.Spec.AuthenticationConfiguration
+	k.Spec.Oidc = body.Spec.Oidc
+	k.Spec.SSHPublicKey = body.Spec.SSHPublicKey
+	k.Status.SpecVersion++
+	if upgrade {
+		s.setPhase(k, models.KlusterPhaseUpgrading)
+	}
+	writeJSON(w, http.StatusOK, k.Kluster)
+}
+
+func (s *Server) terminateCluster(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if k.Status.Phase != models.KlusterPhaseTerminating {
+		s.setPhase(k, models.KlusterPhaseTerminating)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) getCredentials(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	kubeconfig, err := newKubeconfig(k, s.opts.Now(), s.opts.CertificateValidity)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, models.Credentials{Kubeconfig: string(kubeconfig)})
+}
+
+func (s *Server) getKubeadmSecret(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	secret, err := newKubeadmSecret(k)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, models.KubeadmSecret{Secret: string(secret)})
+}
+
+func (s *Server) getEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, k.events)
+}
+
+// get returns the kluster after moving it through the phases which elapsed since the last call.
+// Klusters which finished terminating are removed.
+func (s *Server) get(name string) (*kluster, bool) {
+	k, ok := s.klusters[name]
+	if !ok {
+		return nil, false
+	}
+	now := s.opts.Now()
+	for now.Sub(k.phaseSince) >= s.opts.PhaseDuration {
+		// the next phase started when the previous one elapsed, not when the kluster is read
+		since := k.phaseSince.Add(s.opts.PhaseDuration)
+		switch k.Status.Phase {
+		case models.KlusterPhasePending:
+			s.setPhaseAt(k, models.KlusterPhaseCreating, since)
+		case models.KlusterPhaseCreating, models.KlusterPhaseUpgrading:
+			s.setPhaseAt(k, models.KlusterPhaseRunning, since)
+		case models.KlusterPhaseTerminating:
+			delete(s.klusters, name)
+			return nil, false
+		default:
+			return k, true
+		}
+	}
+	return k, true
+}
+
+func (s *Server) setPhase(k *kluster, phase models.KlusterPhase) {
+	s.setPhaseAt(k, phase, s.opts.Now())
+}
+
+func (s *Server) setPhaseAt(k *kluster, phase models.KlusterPhase, since time.Time) {
+	k.Status.Phase = phase
+	k.phaseSince = since
+	if phase == models.KlusterPhaseRunning {
+		k.Status.ApiserverVersion = k.Spec.Version
+	}
+	s.addEventAt(k, "Normal", string(phase), fmt.Sprintf("Kluster %s is %s", k.Name, phase), since)
+}
+
+func (s *Server) addEvent(k *kluster, eventType, reason, message string) {
+	s.addEventAt(k, eventType, reason, message, s.opts.Now())
+}
+
+func (s *Server) addEventAt(k *kluster, eventType, reason, message string, at time.Time) {
+	now := at.UTC().Format(time.RFC3339)
+	k.events = append(k.events, &models.Event{
+		Count:          1,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body) //nolint:errchkjson // the response is already committed
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, models.Error{Code: int64(code), Message: message})
+}
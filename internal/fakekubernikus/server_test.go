@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package fakekubernikus
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+// clock is a manually advanced time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newClient(t *testing.T, server *TestServer, dex bool) *kubernikus.Client {
+	t.Helper()
+	sec := server.CredentialsSecret("default", "test")
+	if dex {
+		sec = server.DexCredentialsSecret("default", "test")
+	}
+	creds, err := kubernikus.CredentialsFromSecret(sec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client, err := kubernikus.NewClient(creds, kubernikus.TransportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestKlusterLifecycle(t *testing.T) {
+	c := &clock{now: time.Now()}
+	server := NewTestServer(Options{PhaseDuration: time.Minute, Now: c.Now})
+	defer server.Close()
+	kks := newClient(t, server, false)
+	ctx := context.Background()
+	kcp := &v1alpha1.KubernikusControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       v1alpha1.KubernikusControlPlaneSpec{Version: "v1.31.4"},
+	}
+
+	phase := func(expected models.KlusterPhase) {
+		t.Helper()
+		status, err := kks.GetKKSStatus(ctx, kcp, logr.Discard())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if status.Phase != string(expected) {
+			t.Errorf("expected phase %s, got %s", expected, status.Phase)
+		}
+	}
+
+	ensured, err := kks.EnsureControlPlane(ctx, kcp, logr.Discard())
+	if err != nil || !ensured.Created {
+		t.Fatalf("expected the kluster to be created, got %+v, %v", ensured, err)
+	}
+	phase(models.KlusterPhasePending)
+	c.Add(time.Minute)
+	phase(models.KlusterPhaseCreating)
+	c.Add(time.Minute)
+	phase(models.KlusterPhaseRunning)
+	other := kcp.DeepCopy()
+	other.Name = "skipped"
+	if _, err := kks.EnsureControlPlane(ctx, other, logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(2*time.Minute + time.Second)
+	if k, _ := server.Kluster("skipped"); k.Status.Phase != models.KlusterPhaseRunning {
+		t.Errorf("expected elapsed phases to be skipped, got %s", k.Status.Phase)
+	}
+
+	ep, err := kks.GetKKSEndpoint(ctx, kcp)
+	if err != nil || ep.Host != "test."+DefaultDomain {
+		t.Errorf("unexpected endpoint %+v, %v", ep, err)
+	}
+
+	kcp.Spec.Version = "v1.32.1"
+	ensured, err = kks.EnsureControlPlane(ctx, kcp, logr.Discard())
+	if err != nil || len(ensured.UpdatedFields) == 0 {
+		t.Fatalf("expected the kluster to be updated, got %+v, %v", ensured, err)
+	}
+	phase(models.KlusterPhaseUpgrading)
+	newer := kcp.DeepCopy()
+	newer.Spec.Version = "v1.33.0"
+	if _, err := kks.EnsureControlPlane(ctx, newer, logr.Discard()); err == nil || kubernikus.AsTerminal(err) != nil {
+		t.Errorf("expected a transient error for version changes while upgrading, got %v", err)
+	}
+	c.Add(time.Minute)
+	status, err := kks.GetKKSStatus(ctx, kcp, logr.Discard())
+	if err != nil || status.Phase != string(models.KlusterPhaseRunning) || status.Version != "v1.32.1" {
+		t.Errorf("expected the upgrade to complete, got %+v, %v", status, err)
+	}
+
+	events, err := kks.GetKKSEvents(ctx, kcp)
+	if err != nil || len(events) != 5 {
+		t.Errorf("expected an event for every phase, got %d, %v", len(events), err)
+	}
+
+	if gone, err := kks.TerminateControlPlane(ctx, kcp, logr.Discard()); gone || err != nil {
+		t.Fatalf("expected the kluster to terminate, got %t, %v", gone, err)
+	}
+	phase(models.KlusterPhaseTerminating)
+	c.Add(time.Minute)
+	if gone, err := kks.TerminateControlPlane(ctx, kcp, logr.Discard()); !gone || err != nil {
+		t.Errorf("expected the kluster to be gone, got %t, %v", gone, err)
+	}
+}
+
+func TestKubeconfigAndCA(t *testing.T) {
+	server := NewTestServer(Options{CertificateValidity: time.Hour})
+	defer server.Close()
+	kks := newClient(t, server, true)
+	ctx := context.Background()
+	kcp := &v1alpha1.KubernikusControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	if _, err := kks.EnsureControlPlane(ctx, kcp, logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kubeconfig, err := kks.GetKKSKubeconfig(ctx, kcp, logr.Discard())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		t.Fatalf("invalid kubeconfig: %v", err)
+	}
+	current := config.Contexts[config.CurrentContext]
+	authInfo := config.AuthInfos[current.AuthInfo]
+	cert, err := tls.X509KeyPair(authInfo.ClientCertificateData, authInfo.ClientKeyData)
+	if err != nil {
+		t.Fatalf("invalid client certificate: %v", err)
+	}
+	if validity := cert.Leaf.NotAfter.Sub(cert.Leaf.NotBefore); validity > time.Hour+time.Minute {
+		t.Errorf("unexpected validity %s", validity)
+	}
+
+	ca, err := kks.GetKKSCa(ctx, kcp, logr.Discard())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	caKey, err := base64.StdEncoding.DecodeString(ca.StringData["tls.key"])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tls.X509KeyPair(config.Clusters[current.Cluster].CertificateAuthorityData, caKey); err != nil {
+		t.Errorf("the CA key does not match the CA of the kubeconfig: %v", err)
+	}
+	if err := cert.Leaf.CheckSignatureFrom(mustParseCA(t, config.Clusters[current.Cluster].CertificateAuthorityData)); err != nil {
+		t.Errorf("the client certificate is not signed by the CA: %v", err)
+	}
+	if n := server.Requests(Login); n != 1 {
+		t.Errorf("expected a single dex login, got %d", n)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := NewTestServer(Options{})
+	defer server.Close()
+	kks := newClient(t, server, false)
+	ctx := context.Background()
+
+	_, err := kks.EnsureControlPlane(ctx, &v1alpha1.KubernikusControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "Invalid_Name"}}, logr.Discard())
+	if failure := kubernikus.AsTerminal(err); failure == nil || failure.Reason != kubernikus.InvalidSpecFailure {
+		t.Errorf("expected an invalid spec, got %v", err)
+	}
+
+	kcp := &v1alpha1.KubernikusControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	server.InjectError(CreateCluster, Error{Code: http.StatusConflict, Message: "quota exceeded", Times: 1})
+	_, err = kks.EnsureControlPlane(ctx, kcp, logr.Discard())
+	if failure := kubernikus.AsTerminal(err); failure == nil || failure.Reason != kubernikus.QuotaExceededFailure {
+		t.Errorf("expected the injected error, got %v", err)
+	}
+	if _, err := kks.EnsureControlPlane(ctx, kcp, logr.Discard()); err != nil {
+		t.Errorf("expected the injected error to be used up, got %v", err)
+	}
+
+	server.InjectError(ListClusters, Error{Code: http.StatusServiceUnavailable, Message: "maintenance"})
+	for range 2 {
+		if _, err := kks.GetKKSStatus(ctx, kcp, logr.Discard()); err == nil || kubernikus.AsTerminal(err) != nil {
+			t.Errorf("expected a transient error, got %v", err)
+		}
+	}
+	server.ClearErrors()
+	if _, err := kks.GetKKSStatus(ctx, kcp, logr.Discard()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	sec := server.CredentialsSecret("default", "test")
+	sec.Data[kubernikus.TokenKey] = []byte("wrong")
+	creds, err := kubernikus.CredentialsFromSecret(sec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unauthorized, err := kubernikus.NewClient(creds, kubernikus.TransportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := unauthorized.GetKKSStatus(ctx, kcp, logr.Discard()); !kubernikus.IsAuthenticationError(err) {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+}
+
+func mustParseCA(t *testing.T, data []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("CA is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("invalid CA: %v", err)
+	}
+	return cert
+}
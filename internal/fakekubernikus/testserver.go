@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package fakekubernikus
+
+import (
+	"encoding/pem"
+	"net/http/httptest"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
+)
+
+// TestServer serves a Server on a local port with a self-signed certificate.
+type TestServer struct {
+	*Server
+	HTTP *httptest.Server
+}
+
+// NewTestServer starts a TestServer, which has to be closed by the caller.
+func NewTestServer(opts Options) *TestServer {
+	s := New(opts)
+	return &TestServer{Server: s, HTTP: httptest.NewTLSServer(s)}
+}
+
+// Close shuts the server down.
+func (t *TestServer) Close() {
+	t.HTTP.Close()
+}
+
+// URL is the base url of the server.
+func (t *TestServer) URL() string {
+	return t.HTTP.URL
+}
+
+// Host is the host of the server, as expected in the credentials.
+func (t *TestServer) Host() string {
+	return strings.TrimPrefix(t.HTTP.URL, "https://")
+}
+
+// CABundle returns the PEM encoded certificate of the server.
+func (t *TestServer) CABundle() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: t.HTTP.Certificate().Raw})
+}
+
+// CredentialsSecret returns a credentials secret authenticating with DefaultToken.
+func (t *TestServer) CredentialsSecret(namespace, name string) *corev1.Secret {
+	return t.secret(namespace, name, map[string]string{
+		kubernikus.AuthModeKey: string(kubernikus.AuthModeToken),
+		kubernikus.TokenKey:    DefaultToken,
+	})
+}
+
+// DexCredentialsSecret returns a credentials secret logging into the fake dex as DefaultUsername.
+func (t *TestServer) DexCredentialsSecret(namespace, name string) *corev1.Secret {
+	return t.secret(namespace, name, map[string]string{
+		kubernikus.AuthModeKey:    string(kubernikus.AuthModeDex),
+		kubernikus.UsernameKey:    DefaultUsername,
+		kubernikus.PasswordKey:    DefaultPassword,
+		kubernikus.ConnectorIDKey: "local",
+		kubernikus.AuthURLKey:     t.URL(),
+	})
+}
+
+func (t *TestServer) secret(namespace, name string, data map[string]string) *corev1.Secret {
+	data[kubernikus.HostKey] = t.Host()
+	data[kubernikus.CABundleKey] = string(t.CABundle())
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		sec.Data[k] = []byte(v)
+	}
+	return sec
+}
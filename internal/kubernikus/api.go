@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+// KubernikusAPI is the part of the kubernikus api the controller works with.
+// It is implemented by Client, tests replace it with fakes.
+type KubernikusAPI interface {
+	// EnsureControlPlane creates the kluster of the control plane or updates its mutable fields.
+	EnsureControlPlane(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (*EnsureResult, error)
+	// GetKKSStatus returns the status of the kluster, which is empty if the kluster does not exist.
+	GetKKSStatus(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (*v1alpha1.KubernikusControlPlaneStatus, error)
+	// GetKKSEndpoint returns the endpoint of the kluster apiserver.
+	GetKKSEndpoint(ctx context.Context, cp *v1alpha1.KubernikusControlPlane) (*v1beta1.APIEndpoint, error)
+	// GetKKSKubeconfig returns an admin kubeconfig of the kluster.
+	GetKKSKubeconfig(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (string, error)
+	// GetKKSCa returns the kubeadm secret of the kluster holding its certificate authorities.
+	GetKKSCa(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (corev1.Secret, error)
+	// TerminateControlPlane terminates the kluster and returns true once it is gone.
+	TerminateControlPlane(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (bool, error)
+	// GetKKSEvents returns the events kubernikus recorded for the kluster.
+	GetKKSEvents(ctx context.Context, cp *v1alpha1.KubernikusControlPlane) ([]*models.Event, error)
+	// InvalidateToken drops the cached token, so the next call logs in again.
+	InvalidateToken()
+}
+
+// APIFactory returns the KubernikusAPI to use with creds.
+type APIFactory func(creds *Credentials) (KubernikusAPI, error)
+
+var _ KubernikusAPI = &Client{}
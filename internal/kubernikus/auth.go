@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// TokenProvider obtains the bearer token sent with requests to the kubernikus api.
+type TokenProvider interface {
+	// Token returns a valid token, logging in again if the previous one expired.
+	// The login is cancelled together with ctx.
+	Token(ctx context.Context) (string, error)
+}
+
+// authHeaderProvider is implemented by TokenProviders whose tokens are not sent as bearer token.
+type authHeaderProvider interface {
+	// authHeader returns the name and value of the header carrying token.
+	authHeader(token string) (string, string)
+}
+
+// tokenIssuer performs a login and returns the issued token.
+// A zero expiry means the expiry is taken from the exp claim of the token, if it is a JWT.
+type tokenIssuer interface {
+	issue(ctx context.Context) (token string, expiresAt time.Time, err error)
+}
+
+// NewTokenProvider creates the TokenProvider for the auth mode of creds, logging in with httpClient.
+// Logins are shared through DefaultTokenCache.
+func NewTokenProvider(creds *Credentials, httpClient *http.Client) (TokenProvider, error) {
+	return newTokenProvider(creds, DefaultTokenCache, httpClient)
+}
+
+func newTokenProvider(creds *Credentials, cache *TokenCache, httpClient *http.Client) (TokenProvider, error) {
+	var issuer tokenIssuer
+	switch creds.AuthMode {
+	case AuthModeDex, "":
+		issuer = &dexTokenIssuer{
+			httpClient:  httpClient,
+			username:    creds.Username,
+			password:    creds.Password,
+			connectorID: creds.ConnectorID,
+			authURL:     creds.LoginURL(),
+		}
+	case AuthModeToken:
+		return staticTokenProvider(creds.Token), nil
+	case AuthModeClientCredentials:
+		issuer = &clientCredentialsTokenIssuer{
+			httpClient: httpClient,
+			config: &clientcredentials.Config{
+				ClientID:     creds.ClientID,
+				ClientSecret: creds.ClientSecret,
+				TokenURL:     creds.TokenURL,
+				Scopes:       creds.Scopes,
+			},
+		}
+	case AuthModeTokenFile:
+		return fileTokenProvider(creds.TokenFile), nil
+	case AuthModeKeystone:
+		var err error
+		issuer, err = newKeystoneTokenIssuer(creds, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", creds.AuthMode)
+	}
+	return &cachedTokenProvider{
+		cache:  cache,
+		key:    creds.fingerprint(),
+		source: creds.Source,
+		issuer: issuer,
+	}, nil
+}
+
+// cachedTokenProvider takes tokens from a TokenCache, which asks the issuer for a new one when required.
+type cachedTokenProvider struct {
+	cache  *TokenCache
+	key    string
+	source string
+	issuer tokenIssuer
+}
+
+func (p *cachedTokenProvider) Token(ctx context.Context) (string, error) {
+	return p.cache.token(ctx, p.key, p.source, p.issuer)
+}
+
+func (p *cachedTokenProvider) authHeader(token string) (string, string) {
+	if h, ok := p.issuer.(authHeaderProvider); ok {
+		return h.authHeader(token)
+	}
+	return "Authorization", "Bearer " + token
+}
+
+// dexTokenIssuer logs into the kubernikus auth service with the dex username and password form.
+type dexTokenIssuer struct {
+	httpClient  *http.Client
+	username    string
+	password    string
+	connectorID string
+	authURL     string
+}
+
+func (p *dexTokenIssuer) issue(ctx context.Context) (string, time.Time, error) {
+	token, err := GetToken(ctx, p.httpClient, p.username, p.password, p.connectorID, p.authURL)
+	return token, time.Time{}, err
+}
+
+// staticTokenProvider always returns the same token, which is rotated by updating the credentials secret.
+type staticTokenProvider string
+
+func (p staticTokenProvider) Token(context.Context) (string, error) {
+	return string(p), nil
+}
+
+// clientCredentialsTokenIssuer obtains tokens with the OAuth2 client credentials grant.
+type clientCredentialsTokenIssuer struct {
+	httpClient *http.Client
+	config     *clientcredentials.Config
+}
+
+func (p *clientCredentialsTokenIssuer) issue(ctx context.Context) (string, time.Time, error) {
+	token, err := p.config.Token(context.WithValue(ctx, oauth2.HTTPClient, p.httpClient))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token.AccessToken, token.Expiry, nil
+}
+
+// fileTokenProvider reads the token from a file, e.g. a projected service account token.
+// The file is read on every call, so tokens rotated by the kubelet are picked up.
+type fileTokenProvider string
+
+func (p fileTokenProvider) Token(context.Context) (string, error) {
+	data, err := os.ReadFile(string(p))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.New("token file " + string(p) + " is empty")
+	}
+	return token, nil
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticTokenProvider(t *testing.T) {
+	tokens, err := NewTokenProvider(&Credentials{AuthMode: AuthModeToken, Token: "static"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token, err := tokens.Token(context.Background())
+	if err != nil || token != "static" {
+		t.Errorf("got %q, %v", token, err)
+	}
+}
+
+func TestFileTokenProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	tokens, err := NewTokenProvider(&Credentials{AuthMode: AuthModeTokenFile, TokenFile: path}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tokens.Token(context.Background()); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	for _, expected := range []string{"first", "rotated"} {
+		if err := os.WriteFile(path, []byte(expected+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		token, err := tokens.Token(context.Background())
+		if err != nil || token != expected {
+			t.Errorf("got %q, %v, expected %q", token, err, expected)
+		}
+	}
+}
+
+func TestClientCredentialsTokenProvider(t *testing.T) {
+	var requests int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" {
+			http.Error(w, "unsupported grant", http.StatusBadRequest)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "controller" || pass != "secret" {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "issued", "token_type": "bearer", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	// the token endpoint is only trusted with the CA bundle
+	httpClient, _, err := TransportOptions{CABundle: certificatePEM(server)}.newHTTPClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tokens, err := NewTokenProvider(&Credentials{
+		AuthMode:     AuthModeClientCredentials,
+		TokenURL:     server.URL,
+		ClientID:     "controller",
+		ClientSecret: "secret",
+	}, httpClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 2 {
+		token, err := tokens.Token(context.Background())
+		if err != nil || token != "issued" {
+			t.Fatalf("got %q, %v", token, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the token to be cached, got %d requests", requests)
+	}
+}
@@ -4,6 +4,8 @@
 package kubernikus
 
 import (
+	"context"
+
 	"github.com/ghodss/yaml"
 	"github.com/go-logr/logr"
 	"github.com/sapcc/kubernikus/pkg/api/client/operations"
@@ -12,11 +14,11 @@ import (
 	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
 )
 
-func (c *Client) GetKKSCa(cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (corev1.Secret, error) {
+func (c *Client) GetKKSCa(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (corev1.Secret, error) {
 	logger.Info("getting ca secret from kubernikus")
-	gccp := operations.NewGetClusterKubeadmSecretParams()
+	gccp := operations.NewGetClusterKubeadmSecretParamsWithContext(ctx)
 	gccp.Name = cp.Name
-	gcco, err := c.kks.Operations.GetClusterKubeadmSecret(gccp, c)
+	gcco, err := c.kks.Operations.GetClusterKubeadmSecret(gccp, c.authInfo(ctx))
 	if err != nil {
 		logger.Error(err, "failed to get ca secret")
 		return corev1.Secret{}, err
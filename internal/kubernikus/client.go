@@ -4,11 +4,16 @@
 package kubernikus
 
 import (
-	"time"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	kksClient "github.com/sapcc/kubernikus/pkg/api/client"
 	"github.com/sapcc/kubernikus/pkg/api/client/operations"
 	"github.com/sapcc/kubernikus/pkg/api/models"
@@ -17,112 +22,156 @@ import (
 )
 
 type Client struct {
-	host        string
-	token       string
-	username    string
-	password    string
-	connectorID string
-	authURL     string
-	tokenTime   time.Time
-	kks         *kksClient.Kubernikus
+	host      string
+	tokens    TokenProvider
+	transport *http.Transport
+	kks       *kksClient.Kubernikus
 }
 
-func NewClient(host, username, password, connectorId, authUrl string) *Client {
+// NewClient creates a client for the kubernikus api using the given credentials.
+// Transport options not set in the credentials are taken from defaults.
+// The client keeps its own connections to kubernikus, use a ClientPool to reuse them between reconciles.
+func NewClient(creds *Credentials, defaults TransportOptions) (*Client, error) {
+	httpClient, transport, err := creds.Transport.withDefaults(defaults).newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := NewTokenProvider(creds, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	rt := httptransport.NewWithClient(creds.Host, kksClient.DefaultBasePath, kksClient.DefaultSchemes, httpClient)
 	return &Client{
-		host:        host,
-		username:    username,
-		password:    password,
-		connectorID: connectorId,
-		authURL:     authUrl,
-		kks:         kksClient.NewHTTPClientWithConfig(nil, kksClient.DefaultTransportConfig().WithHost(host)),
+		host:      creds.Host,
+		tokens:    tokens,
+		transport: transport,
+		kks:       kksClient.New(rt, strfmt.Default),
+	}, nil
+}
+
+// Close releases the idle connections and the cached token of the client.
+// The ClientPool closes clients when evicting them.
+func (c *Client) Close() {
+	c.transport.CloseIdleConnections()
+	if p, ok := c.tokens.(*cachedTokenProvider); ok {
+		p.cache.release(p.key)
 	}
 }
 
-func (c *Client) AuthenticateRequest(req runtime.ClientRequest, reg strfmt.Registry) error {
-	var err error
-	if time.Since(c.tokenTime) > 30*time.Minute {
-		c.token, err = GetToken(c.username, c.password, c.connectorID, c.authURL)
+// authInfo sends the token of the TokenProvider, as bearer token unless the provider asks otherwise.
+// A login required to obtain the token is bound to ctx, as go-swagger does not pass the request context.
+func (c *Client) authInfo(ctx context.Context) runtime.ClientAuthInfoWriter {
+	return runtime.ClientAuthInfoWriterFunc(func(req runtime.ClientRequest, reg strfmt.Registry) error {
+		token, err := c.tokens.Token(ctx)
+		if err != nil && ctx.Err() != nil {
+			// a cancelled login says nothing about the credentials
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrAuthentication, err)
+		}
+
+		name, value := "Authorization", "Bearer "+token
+		if p, ok := c.tokens.(authHeaderProvider); ok {
+			name, value = p.authHeader(token)
+		}
+		err = req.SetHeaderParam(name, value)
 		if err != nil {
 			return err
 		}
-		c.tokenTime = time.Now()
-	}
+		return nil
+	})
+}
 
-	err = req.SetHeaderParam("Authorization", "Bearer "+c.token)
-	if err != nil {
-		return err
+// InvalidateToken drops the cached token of the client, so the next request logs in again.
+// It is used after kubernikus rejected the token.
+func (c *Client) InvalidateToken() {
+	if p, ok := c.tokens.(*cachedTokenProvider); ok {
+		p.cache.invalidate(p.key)
 	}
-	return nil
 }
 
-func (c *Client) EnsureControlPlane(cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) error {
-	lcp := operations.NewListClustersParams()
-	lco, err := c.kks.Operations.ListClusters(lcp, c)
+// EnsureResult describes the outcome of EnsureControlPlane.
+type EnsureResult struct {
+	// Created is true if the kluster has been created in kubernikus.
+	Created bool
+	// UpdatedFields lists the spec fields which have been updated in kubernikus.
+	UpdatedFields []string
+	// DriftedFields describes the immutable fields which differ between the spec and the kluster.
+	DriftedFields []string
+}
+
+// EnsureControlPlane creates the kluster for the control plane or updates its mutable fields.
+// The api calls are cancelled together with ctx.
+func (c *Client) EnsureControlPlane(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (*EnsureResult, error) {
+	ret := &EnsureResult{}
+	auth := c.authInfo(ctx)
+	lcp := operations.NewListClustersParamsWithContext(ctx)
+	lco, err := c.kks.Operations.ListClusters(lcp, auth)
 	if err != nil {
 		logger.Error(err, "failed to get cluster")
-		return err
+		return nil, err
 	}
 	for _, kluster := range lco.Payload {
 		if kluster.Name == cp.Name {
 			logger.Info("cluster already exists")
-			scp := operations.NewShowClusterParams()
+			scp := operations.NewShowClusterParamsWithContext(ctx)
 			scp.Name = cp.Name
-			sco, err := c.kks.Operations.ShowCluster(scp, c)
+			sco, err := c.kks.Operations.ShowCluster(scp, auth)
 			if err != nil {
 				logger.Error(err, "failed to get cluster")
-				return err
-			}
-			// this only updates the kks kluster if the version changes
-			// TODO: revisit this
-
-			var changed bool
-			if sco.Payload.Spec.Version != cp.Spec.Version {
-				changed = true
-				logger.Info("cluster version changed")
+				return nil, err
 			}
-			if string(sco.Payload.Spec.AuthenticationConfiguration) != cp.Spec.AuthenticationConfiguration {
-				changed = true
-				logger.Info("authentication configuration changed")
+			desired := buildKlusterFromControlPlane(cp)
+			ret.DriftedFields = immutableDrift(&desired.Spec, &sco.Payload.Spec)
+			if len(ret.DriftedFields) > 0 {
+				logger.Info("immutable fields drifted", "fields", ret.DriftedFields)
 			}
-			if changed {
-				logger.Info("cluster changed, updating")
-				ucp := operations.NewUpdateClusterParams()
+			ret.UpdatedFields = mutableDiff(&desired.Spec, &sco.Payload.Spec)
+			if len(ret.UpdatedFields) > 0 {
+				logger.Info("cluster changed, updating", "fields", ret.UpdatedFields)
+				ucp := operations.NewUpdateClusterParamsWithContext(ctx)
 				ucp.Name = cp.Name
-				ucp.Body = buildKlusterFromControlPlane(cp)
+				ucp.Body = desired
+				keepImmutableFields(&ucp.Body.Spec, &sco.Payload.Spec)
 				//nolint:errcheck
-				_, err := c.kks.Operations.UpdateCluster(ucp, c)
+				_, err := c.kks.Operations.UpdateCluster(ucp, auth)
 				if err != nil {
 					logger.Error(err, "failed to update cluster")
-					return err
+					return nil, err
 				}
 			}
-			return nil
+			return ret, nil
 		}
 	}
 	logger.Info("cluster does not exist, creating")
-	ncp := operations.NewCreateClusterParams()
+	ncp := operations.NewCreateClusterParamsWithContext(ctx)
 	ncp.Body = buildKlusterFromControlPlane(cp)
-	ncco, err := c.kks.Operations.CreateCluster(ncp, c)
+	ncco, err := c.kks.Operations.CreateCluster(ncp, auth)
 	if err != nil {
 		logger.Error(err, "failed to create cluster")
-		return err
+		return nil, err
 	}
 	logger.Info("cluster created", "name", ncco.Payload.Name)
-	return nil
+	ret.Created = true
+	return ret, nil
 }
 
 func buildKlusterFromControlPlane(cp *v1alpha1.KubernikusControlPlane) *models.Kluster {
-	f := false
-	audit := "stdout"
+	// the fallbacks match the api defaults, in case they have not been applied
+	audit := cp.Spec.Audit
+	if audit == "" {
+		audit = "stdout"
+	}
 	ret := &models.Kluster{
 		Name: cp.Name,
 		Spec: models.KlusterSpec{
 			NoCloud:                     true,
-			Version:                     cp.Spec.Version,
-			CustomCNI:                   true,
-			SeedKubeadm:                 true,
-			Dashboard:                   &f,
-			Dex:                         &f,
+			Version:                     klusterVersion(cp),
+			CustomCNI:                   boolValue(cp.Spec.CustomCNI, true),
+			SeedKubeadm:                 boolValue(cp.Spec.SeedKubeadm, true),
+			Dashboard:                   swag.Bool(boolValue(cp.Spec.Dashboard, false)),
+			Dex:                         swag.Bool(boolValue(cp.Spec.Dex, false)),
 			Audit:                       &audit,
 			AuthenticationConfiguration: models.AuthenticationConfiguration(cp.Spec.AuthenticationConfiguration),
 		},
@@ -161,3 +210,17 @@ func buildKlusterFromControlPlane(cp *v1alpha1.KubernikusControlPlane) *models.K
 
 	return ret
 }
+
+// boolValue dereferences an optional flag of the spec, falling back to def if it is not set.
+func boolValue(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
+
+// klusterVersion returns the version of the control plane in the format kubernikus expects,
+// Cluster API topologies use a "v" prefix which kubernikus does not accept.
+func klusterVersion(cp *v1alpha1.KubernikusControlPlane) string {
+	return strings.TrimPrefix(cp.Spec.Version, "v")
+}
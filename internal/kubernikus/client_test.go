@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+func TestClientCallsAreCancelledWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	kks, err := NewClient(&Credentials{
+		Host:     strings.TrimPrefix(server.URL, "https://"),
+		AuthMode: AuthModeToken,
+		Token:    "static",
+	}, TransportOptions{CABundle: certificatePEM(server)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer kks.Close()
+	kcp := &v1alpha1.KubernikusControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = kks.EnsureControlPlane(ctx, kcp, logr.Discard())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request was not cancelled, returned after %s", elapsed)
+	}
+}
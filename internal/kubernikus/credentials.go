@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AuthMode selects how the controller authenticates against the kubernikus api.
+type AuthMode string
+
+const (
+	// AuthModeDex logs into the kubernikus auth service with a username and password.
+	AuthModeDex AuthMode = "dex"
+	// AuthModeToken sends a static bearer token.
+	AuthModeToken AuthMode = "token"
+	// AuthModeClientCredentials obtains a token with the OAuth2 client credentials grant.
+	AuthModeClientCredentials AuthMode = "client-credentials"
+	// AuthModeTokenFile reads the token from a file, e.g. a projected service account token.
+	AuthModeTokenFile AuthMode = "token-file"
+	// AuthModeKeystone exchanges a keystone application credential for a keystone token.
+	AuthModeKeystone AuthMode = "keystone"
+)
+
+// Keys of the credentials secret.
+const (
+	// HostKey is the host name of the kubernikus api, without scheme.
+	HostKey = "host"
+	// AuthModeKey selects the AuthMode, it defaults to dex.
+	AuthModeKey = "mode"
+
+	// UsernameKey is the user logging into the kubernikus auth service.
+	UsernameKey = "user"
+	// PasswordKey is the password of the user.
+	PasswordKey = "pass"
+	// ConnectorIDKey is the dex connector used for the login.
+	ConnectorIDKey = "conn"
+	// AuthURLKey is the base URL of the kubernikus auth service.
+	AuthURLKey = "auth"
+
+	// TokenKey is the bearer token of the token mode.
+	TokenKey = "token"
+
+	// TokenURLKey is the token endpoint of the OAuth2 server.
+	TokenURLKey = "token-url"
+	// ClientIDKey is the OAuth2 client id.
+	ClientIDKey = "client-id"
+	// ClientSecretKey is the OAuth2 client secret.
+	ClientSecretKey = "client-secret"
+	// ScopesKey is an optional, space separated list of OAuth2 scopes.
+	ScopesKey = "scopes"
+
+	// TokenFileKey is the absolute path of the file holding the token.
+	TokenFileKey = "token-file"
+
+	// CABundleKey holds PEM encoded CA certificates trusted for kubernikus and its auth services.
+	CABundleKey = "ca.crt"
+	// InsecureSkipTLSVerifyKey disables the verification of server certificates if set to true.
+	InsecureSkipTLSVerifyKey = "insecure-skip-tls-verify"
+	// ProxyURLKey is the http proxy used to reach kubernikus and its auth services.
+	ProxyURLKey = "proxy-url"
+	// NoProxyKey is a comma separated list of hosts reached without the proxy.
+	NoProxyKey = "no-proxy"
+	// TimeoutKey bounds each request, e.g. 30s.
+	TimeoutKey = "timeout"
+
+	// KeystoneURLKey is the keystone v3 endpoint, e.g. https://identity.example.com/v3.
+	KeystoneURLKey = "keystone-url"
+	// ApplicationCredentialIDKey identifies the application credential by id.
+	ApplicationCredentialIDKey = "application-credential-id"
+	// ApplicationCredentialNameKey identifies the application credential by name, together with its user.
+	ApplicationCredentialNameKey = "application-credential-name"
+	// ApplicationCredentialSecretKey is the secret of the application credential.
+	ApplicationCredentialSecretKey = "application-credential-secret"
+	// UserIDKey is the id of the user owning the application credential.
+	UserIDKey = "user-id"
+	// UserNameKey is the name of the user owning the application credential.
+	UserNameKey = "user-name"
+	// UserDomainIDKey is the id of the domain of the user.
+	UserDomainIDKey = "user-domain-id"
+	// UserDomainNameKey is the name of the domain of the user.
+	UserDomainNameKey = "user-domain-name"
+)
+
+// Credentials are the validated contents of a kubernikus credentials secret.
+// Which fields are set depends on the AuthMode.
+type Credentials struct {
+	// Source is the namespace and name of the secret the credentials have been read from.
+	Source string `json:"-"`
+
+	Host     string
+	AuthMode AuthMode
+
+	Username    string
+	Password    string
+	ConnectorID string
+	AuthURL     string
+
+	Token string
+
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	TokenFile string
+
+	KeystoneURL                 string
+	ApplicationCredentialID     string
+	ApplicationCredentialName   string
+	ApplicationCredentialSecret string
+	UserID                      string
+	UserName                    string
+	UserDomainID                string
+	UserDomainName              string
+
+	// Transport overrides the controller wide transport options.
+	Transport TransportOptions
+}
+
+// LoginURL is the URL of the login endpoint of the auth service.
+func (c *Credentials) LoginURL() string {
+	return strings.TrimSuffix(c.AuthURL, "/") + "/auth/login"
+}
+
+// fingerprint identifies the credentials, independent of the secret they have been read from.
+func (c *Credentials) fingerprint() string {
+	data, _ := json.Marshal(c) //nolint:errchkjson // Credentials only holds strings
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CredentialsFromSecret parses and validates the credentials stored in sec.
+// The error lists every missing or malformed key.
+func CredentialsFromSecret(sec *corev1.Secret) (*Credentials, error) {
+	var problems []string
+	optional := func(key string) string {
+		return strings.TrimSpace(string(sec.Data[key]))
+	}
+	required := func(key string) string {
+		if _, ok := sec.Data[key]; !ok {
+			problems = append(problems, fmt.Sprintf("missing key %q", key))
+			return ""
+		}
+		ret := optional(key)
+		if ret == "" {
+			problems = append(problems, fmt.Sprintf("key %q is empty", key))
+		}
+		return ret
+	}
+	creds := &Credentials{
+		Source:   sec.Namespace + "/" + sec.Name,
+		Host:     required(HostKey),
+		AuthMode: AuthMode(optional(AuthModeKey)),
+	}
+	if creds.AuthMode == "" {
+		creds.AuthMode = AuthModeDex
+	}
+
+	if creds.Host != "" {
+		u, err := url.Parse("//" + creds.Host)
+		if err != nil || u.Host != creds.Host {
+			problems = append(problems, fmt.Sprintf("key %q must be a host name without scheme or path, got %q", HostKey, creds.Host))
+		}
+	}
+
+	switch creds.AuthMode {
+	case AuthModeDex:
+		creds.Username = required(UsernameKey)
+		creds.Password = required(PasswordKey)
+		creds.ConnectorID = required(ConnectorIDKey)
+		creds.AuthURL = required(AuthURLKey)
+		problems = append(problems, validateURL(AuthURLKey, creds.AuthURL)...)
+	case AuthModeToken:
+		creds.Token = required(TokenKey)
+	case AuthModeClientCredentials:
+		creds.TokenURL = required(TokenURLKey)
+		creds.ClientID = required(ClientIDKey)
+		creds.ClientSecret = required(ClientSecretKey)
+		creds.Scopes = strings.Fields(optional(ScopesKey))
+		problems = append(problems, validateURL(TokenURLKey, creds.TokenURL)...)
+	case AuthModeTokenFile:
+		creds.TokenFile = required(TokenFileKey)
+		if creds.TokenFile != "" && !filepath.IsAbs(creds.TokenFile) {
+			problems = append(problems, fmt.Sprintf("key %q must be an absolute path, got %q", TokenFileKey, creds.TokenFile))
+		}
+	case AuthModeKeystone:
+		creds.KeystoneURL = required(KeystoneURLKey)
+		creds.ApplicationCredentialSecret = required(ApplicationCredentialSecretKey)
+		problems = append(problems, validateURL(KeystoneURLKey, creds.KeystoneURL)...)
+		creds.ApplicationCredentialID = optional(ApplicationCredentialIDKey)
+		if creds.ApplicationCredentialID != "" {
+			break
+		}
+		// without an id the application credential is looked up by name in the credentials of its user
+		creds.ApplicationCredentialName = optional(ApplicationCredentialNameKey)
+		creds.UserID = optional(UserIDKey)
+		creds.UserName = optional(UserNameKey)
+		creds.UserDomainID = optional(UserDomainIDKey)
+		creds.UserDomainName = optional(UserDomainNameKey)
+		switch {
+		case creds.ApplicationCredentialName == "":
+			problems = append(problems, fmt.Sprintf("either key %q or key %q is required", ApplicationCredentialIDKey, ApplicationCredentialNameKey))
+		case creds.UserID == "" && creds.UserName == "":
+			problems = append(problems, fmt.Sprintf("key %q requires key %q or key %q", ApplicationCredentialNameKey, UserIDKey, UserNameKey))
+		case creds.UserID == "" && creds.UserDomainID == "" && creds.UserDomainName == "":
+			problems = append(problems, fmt.Sprintf("key %q requires key %q or key %q", UserNameKey, UserDomainIDKey, UserDomainNameKey))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("key %q has unknown auth mode %q, expected one of %s, %s, %s, %s or %s",
+			AuthModeKey, creds.AuthMode, AuthModeDex, AuthModeToken, AuthModeClientCredentials, AuthModeTokenFile, AuthModeKeystone))
+	}
+
+	creds.Transport.CABundle = sec.Data[CABundleKey]
+	creds.Transport.ProxyURL = optional(ProxyURLKey)
+	creds.Transport.NoProxy = optional(NoProxyKey)
+	if v := optional(InsecureSkipTLSVerifyKey); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("key %q must be true or false, got %q", InsecureSkipTLSVerifyKey, v))
+		}
+		creds.Transport.InsecureSkipVerify = insecure
+	}
+	if v := optional(TimeoutKey); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			problems = append(problems, fmt.Sprintf("key %q must be a positive duration like 30s, got %q", TimeoutKey, v))
+		}
+		creds.Transport.Timeout = timeout
+	}
+	if err := creds.Transport.validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid credentials in secret %s/%s: %s", sec.Namespace, sec.Name, strings.Join(problems, "; "))
+	}
+	return creds, nil
+}
+
+// CheckTokenFile makes sure the token file of the token-file mode is in one of dirs.
+// The controller reads the file with its own permissions, so without dirs the mode is rejected.
+func (c *Credentials) CheckTokenFile(dirs []string) error {
+	if c.AuthMode != AuthModeTokenFile {
+		return nil
+	}
+	path := filepath.Clean(c.TokenFile)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(filepath.Clean(dir), path)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	if len(dirs) == 0 {
+		return fmt.Errorf("invalid credentials in secret %s: auth mode %s is disabled, no token file directories are allowed", c.Source, AuthModeTokenFile)
+	}
+	return fmt.Errorf("invalid credentials in secret %s: key %q must be a file in %s, got %q",
+		c.Source, TokenFileKey, strings.Join(dirs, ", "), c.TokenFile)
+}
+
+// validateURL checks that the value of key is an absolute http(s) URL.
+func validateURL(key, value string) []string {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return []string{fmt.Sprintf("key %q must be an absolute http(s) URL, got %q", key, value)}
+	}
+	return nil
+}
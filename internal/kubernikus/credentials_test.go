@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func credentialsSecret(data map[string]string) *corev1.Secret {
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kubernikus"},
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		sec.Data[k] = []byte(v)
+	}
+	return sec
+}
+
+func validCredentials() map[string]string {
+	return map[string]string{
+		HostKey:        "kubernikus.example.com",
+		UsernameKey:    "technical-user",
+		PasswordKey:    "secret\n",
+		ConnectorIDKey: "keystone",
+		AuthURLKey:     "https://auth.example.com",
+	}
+}
+
+func TestCredentialsFromSecret(t *testing.T) {
+	creds, err := CredentialsFromSecret(credentialsSecret(validCredentials()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.Password != "secret" {
+		t.Errorf("expected trailing newline to be trimmed, got %q", creds.Password)
+	}
+	if creds.LoginURL() != "https://auth.example.com/auth/login" {
+		t.Errorf("unexpected login url %s", creds.LoginURL())
+	}
+}
+
+func TestCredentialsFromSecretModes(t *testing.T) {
+	creds, err := CredentialsFromSecret(credentialsSecret(map[string]string{
+		HostKey:         "kubernikus.example.com",
+		AuthModeKey:     string(AuthModeClientCredentials),
+		TokenURLKey:     "https://auth.example.com/token",
+		ClientIDKey:     "controller",
+		ClientSecretKey: "secret",
+		ScopesKey:       "openid kubernikus",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(creds.Scopes) != 2 || creds.Username != "" {
+		t.Errorf("unexpected credentials %+v", creds)
+	}
+}
+
+func TestCredentialsFromSecretErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(map[string]string)
+		expected []string
+	}{
+		{
+			name: "missing keys",
+			mutate: func(data map[string]string) {
+				delete(data, UsernameKey)
+				delete(data, PasswordKey)
+			},
+			expected: []string{`missing key "user"`, `missing key "pass"`},
+		},
+		{
+			name:     "empty key",
+			mutate:   func(data map[string]string) { data[ConnectorIDKey] = "\n" },
+			expected: []string{`key "conn" is empty`},
+		},
+		{
+			name:     "host with scheme",
+			mutate:   func(data map[string]string) { data[HostKey] = "https://kubernikus.example.com" },
+			expected: []string{`key "host" must be a host name`},
+		},
+		{
+			name:     "unknown mode",
+			mutate:   func(data map[string]string) { data[AuthModeKey] = "kerberos" },
+			expected: []string{`unknown auth mode "kerberos"`},
+		},
+		{
+			name: "token mode without token",
+			mutate: func(data map[string]string) {
+				data[AuthModeKey] = string(AuthModeToken)
+			},
+			expected: []string{`missing key "token"`},
+		},
+		{
+			name: "client credentials without secret",
+			mutate: func(data map[string]string) {
+				data[AuthModeKey] = string(AuthModeClientCredentials)
+				data[TokenURLKey] = "https://auth.example.com/token"
+				data[ClientIDKey] = "controller"
+			},
+			expected: []string{`missing key "client-secret"`},
+		},
+		{
+			name: "relative token file",
+			mutate: func(data map[string]string) {
+				data[AuthModeKey] = string(AuthModeTokenFile)
+				data[TokenFileKey] = "token"
+			},
+			expected: []string{`key "token-file" must be an absolute path`},
+		},
+		{
+			name: "keystone without application credential",
+			mutate: func(data map[string]string) {
+				data[AuthModeKey] = string(AuthModeKeystone)
+				data[KeystoneURLKey] = "https://identity.example.com/v3"
+				data[ApplicationCredentialSecretKey] = "secret"
+			},
+			expected: []string{`either key "application-credential-id" or key "application-credential-name" is required`},
+		},
+		{
+			name: "keystone user without domain",
+			mutate: func(data map[string]string) {
+				data[AuthModeKey] = string(AuthModeKeystone)
+				data[KeystoneURLKey] = "https://identity.example.com/v3"
+				data[ApplicationCredentialNameKey] = "controller"
+				data[ApplicationCredentialSecretKey] = "secret"
+				data[UserNameKey] = "technical-user"
+			},
+			expected: []string{`key "user-name" requires key "user-domain-id" or key "user-domain-name"`},
+		},
+		{
+			name: "malformed transport options",
+			mutate: func(data map[string]string) {
+				data[InsecureSkipTLSVerifyKey] = "yes please"
+				data[TimeoutKey] = "30"
+				data[CABundleKey] = "not a certificate"
+			},
+			expected: []string{
+				`key "insecure-skip-tls-verify" must be true or false`,
+				`key "timeout" must be a positive duration`,
+				"CA bundle does not contain any PEM encoded certificate",
+			},
+		},
+		{
+			name:     "relative auth url",
+			mutate:   func(data map[string]string) { data[AuthURLKey] = "auth.example.com" },
+			expected: []string{`key "auth" must be an absolute http(s) URL`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := validCredentials()
+			tt.mutate(data)
+			_, err := CredentialsFromSecret(credentialsSecret(data))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), "default/kubernikus") {
+				t.Errorf("expected error to name the secret, got %v", err)
+			}
+			for _, e := range tt.expected {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected error to contain %q, got %v", e, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCredentialsCheckTokenFile(t *testing.T) {
+	dirs := []string{"/var/run/secrets/kubernikus"}
+	for path, allowed := range map[string]bool{
+		"/var/run/secrets/kubernikus/token":                   true,
+		"/var/run/secrets/kubernikus/..data/token":            true,
+		"/var/run/secrets/kubernikus":                         false,
+		"/var/run/secrets/kubernikus/../token":                false,
+		"/var/run/secrets/kubernikus-other/token":             false,
+		"/var/run/secrets/kubernetes.io/serviceaccount/token": false,
+	} {
+		creds := &Credentials{Source: "kubernikus-system/identity", AuthMode: AuthModeTokenFile, TokenFile: path}
+		err := creds.CheckTokenFile(dirs)
+		if allowed != (err == nil) {
+			t.Errorf("%s: expected allowed=%t, got %v", path, allowed, err)
+		}
+	}
+
+	creds := &Credentials{Source: "kubernikus-system/identity", AuthMode: AuthModeTokenFile, TokenFile: "/var/run/secrets/kubernikus/token"}
+	if err := creds.CheckTokenFile(nil); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("expected the mode to be disabled without directories, got %v", err)
+	}
+	creds = &Credentials{AuthMode: AuthModeToken}
+	if err := creds.CheckTokenFile(nil); err != nil {
+		t.Errorf("unexpected error for other modes: %v", err)
+	}
+}
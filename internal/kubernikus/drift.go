@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-openapi/swag"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+)
+
+// immutableDrift compares the fields kubernikus can not update on a running kluster.
+// Fields left empty in the desired spec are defaulted by kubernikus and therefore skipped.
+// It returns a description for every drifted field.
+func immutableDrift(desired, live *models.KlusterSpec) []string {
+	var drift []string
+	check := func(name, want, have string) {
+		if want != "" && want != have {
+			drift = append(drift, fmt.Sprintf("%s: spec has %q, kluster has %q", name, want, have))
+		}
+	}
+	check("serviceCidr", desired.ServiceCIDR, live.ServiceCIDR)
+	if desired.ClusterCIDR != nil {
+		var have string
+		if live.ClusterCIDR != nil {
+			have = *live.ClusterCIDR
+		}
+		check("clusterCidr", *desired.ClusterCIDR, have)
+	}
+	check("dnsDomain", desired.DNSDomain, live.DNSDomain)
+	check("dnsAddress", desired.DNSAddress, live.DNSAddress)
+	// kubernikus only honours these settings on creation
+	check("backup", desired.Backup, live.Backup)
+	check("customCNI", strconv.FormatBool(desired.CustomCNI), strconv.FormatBool(live.CustomCNI))
+	check("seedKubeadm", strconv.FormatBool(desired.SeedKubeadm), strconv.FormatBool(live.SeedKubeadm))
+	return drift
+}
+
+// mutableDiff compares the fields kubernikus applies on an update of the kluster.
+// It returns the names of the changed fields as used in the KubernikusControlPlane spec.
+func mutableDiff(desired, live *models.KlusterSpec) []string {
+	var changed []string
+	if desired.Version != live.Version {
+		changed = append(changed, "version")
+	}
+	if desired.AuthenticationConfiguration != live.AuthenticationConfiguration {
+		changed = append(changed, "authenticationConfiguration")
+	}
+	if !oidcEqual(desired.Oidc, live.Oidc) {
+		changed = append(changed, "oidc")
+	}
+	if desired.SSHPublicKey != live.SSHPublicKey {
+		changed = append(changed, "sshPublicKey")
+	}
+	if swag.StringValue(desired.Audit) != swag.StringValue(live.Audit) {
+		changed = append(changed, "audit")
+	}
+	if swag.BoolValue(desired.Dex) != swag.BoolValue(live.Dex) {
+		changed = append(changed, "dex")
+	}
+	if swag.BoolValue(desired.Dashboard) != swag.BoolValue(live.Dashboard) {
+		changed = append(changed, "dashboard")
+	}
+	return changed
+}
+
+// oidcEqual treats a missing oidc configuration like an empty one, as kubernikus does not distinguish them.
+func oidcEqual(a, b *models.OIDC) bool {
+	var x, y models.OIDC
+	if a != nil {
+		x = *a
+	}
+	if b != nil {
+		y = *b
+	}
+	return x.IssuerURL == y.IssuerURL && x.ClientID == y.ClientID
+}
+
+// keepImmutableFields copies the fields kubernikus can not update from the live kluster into an update request.
+func keepImmutableFields(update, live *models.KlusterSpec) {
+	update.ServiceCIDR = live.ServiceCIDR
+	update.ClusterCIDR = live.ClusterCIDR
+	update.DNSDomain = live.DNSDomain
+	update.DNSAddress = live.DNSAddress
+	update.Backup = live.Backup
+	update.CustomCNI = live.CustomCNI
+	update.SeedKubeadm = live.SeedKubeadm
+}
@@ -4,6 +4,7 @@
 package kubernikus
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/sapcc/kubernikus/pkg/api/client/operations"
@@ -12,10 +13,11 @@ import (
 	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
 )
 
-func (c *Client) GetKKSEndpoint(cp *v1alpha1.KubernikusControlPlane) (*v1beta1.APIEndpoint, error) {
+func (c *Client) GetKKSEndpoint(ctx context.Context, cp *v1alpha1.KubernikusControlPlane) (*v1beta1.APIEndpoint, error) {
 	ret := v1beta1.APIEndpoint{}
-	scp := operations.ShowClusterParams{Name: cp.Name}
-	sco, err := c.kks.Operations.ShowCluster(&scp, c)
+	scp := operations.NewShowClusterParamsWithContext(ctx)
+	scp.Name = cp.Name
+	sco, err := c.kks.Operations.ShowCluster(scp, c.authInfo(ctx))
 	if err != nil {
 		return nil, err
 	}
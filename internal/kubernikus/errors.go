@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+)
+
+// ErrAuthentication is returned when no token could be obtained from the kubernikus auth service.
+var ErrAuthentication = errors.New("authentication failed")
+
+// Reasons of terminal failures, used for the FailureReason of the KubernikusControlPlane.
+const (
+	// InvalidSpecFailure is used when kubernikus rejected the kluster spec.
+	InvalidSpecFailure = "InvalidSpec"
+	// QuotaExceededFailure is used when the project has no quota left for the kluster.
+	QuotaExceededFailure = "QuotaExceeded"
+	// NameConflictFailure is used when the kluster name is already taken.
+	NameConflictFailure = "NameConflict"
+	// RequestRejectedFailure is used for all other client errors which retrying will not fix.
+	RequestRejectedFailure = "RequestRejected"
+)
+
+// TerminalError is an error response of the kubernikus api which will not go away by retrying
+// the same request. It is resolved by changing the spec or the project in kubernikus.
+type TerminalError struct {
+	// Reason is a CamelCase classification of the failure.
+	Reason string
+	// Code is the http status code of the response.
+	Code int
+	// Message is the error message returned by kubernikus.
+	Message string
+
+	err error
+}
+
+func (e *TerminalError) Error() string {
+	return e.Reason + ": " + e.Message
+}
+
+func (e *TerminalError) Unwrap() error {
+	return e.err
+}
+
+// AsTerminal classifies err and returns the TerminalError describing it, or nil if the error is transient.
+// Server errors, timeouts, throttling, missing resources and rejected credentials are transient.
+func AsTerminal(err error) *TerminalError {
+	if err == nil {
+		return nil
+	}
+	var terminal *TerminalError
+	if errors.As(err, &terminal) {
+		return terminal
+	}
+	code, message, ok := apiError(err)
+	if !ok || code < 400 || code >= 500 {
+		return nil
+	}
+	ret := &TerminalError{Code: code, Message: message, err: err}
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		// retrying can succeed once the token has been renewed, the kluster shows up or the api recovers
+		return nil
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		if slices.Contains(phasePreconditions, message) {
+			// e.g. version changes are rejected until the kluster is running again
+			return nil
+		}
+		ret.Reason = InvalidSpecFailure
+	case http.StatusConflict:
+		ret.Reason = NameConflictFailure
+	default:
+		ret.Reason = RequestRejectedFailure
+	}
+	if strings.Contains(strings.ToLower(message), "quota") {
+		ret.Reason = QuotaExceededFailure
+	}
+	return ret
+}
+
+// phasePreconditions are the messages kubernikus rejects requests with because of the current phase
+// of the kluster. They do not carry an error code of their own.
+var phasePreconditions = []string{
+	fmt.Sprintf("Version can be changed in state %s only", models.KlusterPhaseRunning),
+}
+
+// IsAuthenticationError reports whether err was caused by credentials which have been rejected,
+// either by the auth service or by the kubernikus api.
+func IsAuthenticationError(err error) bool {
+	if errors.Is(err, ErrAuthentication) {
+		return true
+	}
+	code, _, ok := apiError(err)
+	return ok && (code == http.StatusUnauthorized || code == http.StatusForbidden)
+}
+
+// apiError extracts the status code and message from the error responses of the go-swagger client.
+// Those are either the typed *Default responses carrying a models.Error or a runtime.APIError
+// for responses the client does not know.
+func apiError(err error) (int, string, bool) {
+	var typed interface {
+		Code() int
+		GetPayload() *models.Error
+	}
+	if errors.As(err, &typed) {
+		if payload := typed.GetPayload(); payload != nil && payload.Message != "" {
+			return typed.Code(), payload.Message, true
+		}
+		return typed.Code(), http.StatusText(typed.Code()), true
+	}
+	var untyped *runtime.APIError
+	if errors.As(err, &untyped) {
+		return untyped.Code, http.StatusText(untyped.Code), true
+	}
+	return 0, "", false
+}
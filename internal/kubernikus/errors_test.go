@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/sapcc/kubernikus/pkg/api/client/operations"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+)
+
+func createClusterDefault(code int, message string) error {
+	ret := operations.NewCreateClusterDefault(code)
+	ret.Payload = &models.Error{Code: int64(code), Message: message}
+	return ret
+}
+
+func TestAsTerminal(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		reason  string
+		message string
+	}{
+		{name: "nil", err: nil},
+		{name: "plain error", err: errors.New("connection refused")},
+		{name: "server error", err: createClusterDefault(500, "internal error")},
+		{name: "unauthorized", err: createClusterDefault(401, "token expired")},
+		{name: "throttled", err: createClusterDefault(429, "slow down")},
+		{name: "phase precondition", err: createClusterDefault(400, "Version can be changed in state Running only")},
+		{name: "phase in message", err: createClusterDefault(400, "Can't upgrade from version 1.30.1 to 1.32.0 in state Running"), reason: InvalidSpecFailure, message: "Can't upgrade from version 1.30.1 to 1.32.0 in state Running"},
+		{name: "invalid spec", err: createClusterDefault(400, "invalid cidr"), reason: InvalidSpecFailure, message: "invalid cidr"},
+		{name: "unprocessable", err: createClusterDefault(422, "bad version"), reason: InvalidSpecFailure, message: "bad version"},
+		{name: "conflict", err: createClusterDefault(409, "name already taken"), reason: NameConflictFailure, message: "name already taken"},
+		{name: "quota", err: createClusterDefault(409, "Quota exceeded for instances"), reason: QuotaExceededFailure, message: "Quota exceeded for instances"},
+		{name: "wrapped", err: fmt.Errorf("create: %w", createClusterDefault(400, "invalid")), reason: InvalidSpecFailure, message: "invalid"},
+		{name: "unknown response", err: runtime.NewAPIError("unknown", nil, 405), reason: RequestRejectedFailure, message: "Method Not Allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := AsTerminal(tt.err)
+			if tt.reason == "" {
+				if failure != nil {
+					t.Fatalf("expected transient error, got %v", failure)
+				}
+				return
+			}
+			if failure == nil {
+				t.Fatalf("expected terminal error with reason %s", tt.reason)
+			}
+			if failure.Reason != tt.reason || failure.Message != tt.message {
+				t.Errorf("got %s/%q, expected %s/%q", failure.Reason, failure.Message, tt.reason, tt.message)
+			}
+		})
+	}
+}
+
+func TestIsAuthenticationError(t *testing.T) {
+	if !IsAuthenticationError(fmt.Errorf("%w: wrong password", ErrAuthentication)) {
+		t.Error("expected login failure to be an authentication error")
+	}
+	if !IsAuthenticationError(createClusterDefault(401, "unauthorized")) {
+		t.Error("expected 401 to be an authentication error")
+	}
+	if IsAuthenticationError(createClusterDefault(400, "invalid")) {
+		t.Error("expected 400 not to be an authentication error")
+	}
+}
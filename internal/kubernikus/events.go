@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+
+	"github.com/sapcc/kubernikus/pkg/api/client/operations"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+// GetKKSEvents returns the events kubernikus recorded for the kluster of the control plane.
+func (c *Client) GetKKSEvents(ctx context.Context, cp *v1alpha1.KubernikusControlPlane) ([]*models.Event, error) {
+	gcep := operations.NewGetClusterEventsParamsWithContext(ctx)
+	gcep.Name = cp.Name
+	gceo, err := c.kks.Operations.GetClusterEvents(gcep, c.authInfo(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return gceo.Payload, nil
+}
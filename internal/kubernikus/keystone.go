@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// keystoneTokenIssuer exchanges a keystone application credential for a token,
+// which kubernikus accepts in the X-Auth-Token header.
+type keystoneTokenIssuer struct {
+	url    string
+	body   []byte
+	client *http.Client
+}
+
+type keystoneAuthRequest struct {
+	Auth struct {
+		Identity struct {
+			Methods               []string                      `json:"methods"`
+			ApplicationCredential keystoneApplicationCredential `json:"application_credential"`
+		} `json:"identity"`
+	} `json:"auth"`
+}
+
+type keystoneApplicationCredential struct {
+	ID     string        `json:"id,omitempty"`
+	Name   string        `json:"name,omitempty"`
+	Secret string        `json:"secret"`
+	User   *keystoneUser `json:"user,omitempty"`
+}
+
+type keystoneUser struct {
+	ID     string          `json:"id,omitempty"`
+	Name   string          `json:"name,omitempty"`
+	Domain *keystoneDomain `json:"domain,omitempty"`
+}
+
+type keystoneDomain struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+func newKeystoneTokenIssuer(creds *Credentials, httpClient *http.Client) (*keystoneTokenIssuer, error) {
+	var req keystoneAuthRequest
+	req.Auth.Identity.Methods = []string{"application_credential"}
+	appCred := keystoneApplicationCredential{
+		ID:     creds.ApplicationCredentialID,
+		Secret: creds.ApplicationCredentialSecret,
+	}
+	// an application credential is either identified by its id or by its name and owner
+	if appCred.ID == "" {
+		appCred.Name = creds.ApplicationCredentialName
+		appCred.User = &keystoneUser{ID: creds.UserID}
+		if creds.UserID == "" {
+			appCred.User.Name = creds.UserName
+			appCred.User.Domain = &keystoneDomain{ID: creds.UserDomainID, Name: creds.UserDomainName}
+		}
+	}
+	req.Auth.Identity.ApplicationCredential = appCred
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return &keystoneTokenIssuer{
+		url:    strings.TrimSuffix(creds.KeystoneURL, "/") + "/auth/tokens",
+		body:   body,
+		client: httpClient,
+	}, nil
+}
+
+// authHeader sends the token as keystone token instead of a bearer token.
+func (p *keystoneTokenIssuer) authHeader(token string) (string, string) {
+	return "X-Auth-Token", token
+}
+
+// issue requests a new token from keystone, which expires at the time given in the response.
+func (p *keystoneTokenIssuer) issue(ctx context.Context) (string, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(p.body))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to build keystone request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req) //nolint:gosec // the keystone url is set from kube secrets
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to call %s: %w", p.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", time.Time{}, fmt.Errorf("calling %s failed with %s: %s", p.url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	token := resp.Header.Get("X-Subject-Token")
+	if token == "" {
+		return "", time.Time{}, errors.New("keystone response lacks the X-Subject-Token header")
+	}
+	var body struct {
+		Token struct {
+			ExpiresAt time.Time `json:"expires_at"`
+		} `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode keystone response: %w", err)
+	}
+	return token, body.Token.ExpiresAt, nil
+}
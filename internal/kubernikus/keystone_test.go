@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestKeystoneTokenProvider(t *testing.T) {
+	var issued int
+	lifetime := time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/auth/tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		var req keystoneAuthRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		appCred := req.Auth.Identity.ApplicationCredential
+		if appCred.Name != "controller" || appCred.User == nil || appCred.User.Name != "technical-user" ||
+			appCred.User.Domain == nil || appCred.User.Domain.Name != "Default" || appCred.Secret != "secret" {
+			http.Error(w, "invalid application credential", http.StatusUnauthorized)
+			return
+		}
+		issued++
+		w.Header().Set("X-Subject-Token", fmt.Sprintf("token-%d", issued))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token": map[string]any{"expires_at": time.Now().Add(lifetime).UTC().Format(time.RFC3339)},
+		})
+	}))
+	defer server.Close()
+
+	cache := NewTokenCache()
+	tokens, err := newTokenProvider(&Credentials{
+		AuthMode:                    AuthModeKeystone,
+		KeystoneURL:                 server.URL + "/v3",
+		ApplicationCredentialName:   "controller",
+		ApplicationCredentialSecret: "secret",
+		UserName:                    "technical-user",
+		UserDomainName:              "Default",
+	}, cache, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for range 2 {
+		token, err := tokens.Token(context.Background())
+		if err != nil || token != "token-1" {
+			t.Fatalf("got %q, %v, expected cached token-1", token, err)
+		}
+	}
+	name, value := tokens.(authHeaderProvider).authHeader("token-1")
+	if name != "X-Auth-Token" || value != "token-1" {
+		t.Errorf("unexpected header %s: %s", name, value)
+	}
+
+	// tokens expiring soon are renewed
+	cache.now = func() time.Time { return time.Now().Add(lifetime - time.Minute) }
+	token, err := tokens.Token(context.Background())
+	if err != nil || token != "token-2" {
+		t.Fatalf("got %q, %v, expected renewed token-2", token, err)
+	}
+}
+
+func TestKeystoneTokenProviderRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "The request you have made requires authentication.", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	tokens, err := newTokenProvider(&Credentials{
+		AuthMode:                    AuthModeKeystone,
+		KeystoneURL:                 server.URL + "/v3",
+		ApplicationCredentialID:     "id",
+		ApplicationCredentialSecret: "wrong",
+	}, NewTokenCache(), http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tokens.Token(context.Background()); err == nil {
+		t.Error("expected an error for rejected credentials")
+	}
+}
@@ -4,17 +4,19 @@
 package kubernikus
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	"github.com/sapcc/kubernikus/pkg/api/client/operations"
 
 	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
 )
 
-func (c *Client) GetKKSKubeconfig(cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (string, error) {
+func (c *Client) GetKKSKubeconfig(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (string, error) {
 	logger.Info("getting kubeconfig from kubernikus")
-	gccp := operations.NewGetClusterCredentialsParams()
+	gccp := operations.NewGetClusterCredentialsParamsWithContext(ctx)
 	gccp.Name = cp.Name
-	gcco, err := c.kks.Operations.GetClusterCredentials(gccp, c)
+	gcco, err := c.kks.Operations.GetClusterCredentials(gccp, c.authInfo(ctx))
 	if err != nil {
 		logger.Error(err, "failed to get kubeconfig")
 		return "", err
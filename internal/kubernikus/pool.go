@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultClientIdleTimeout is how long a pooled client is kept without being used.
+const DefaultClientIdleTimeout = 30 * time.Minute
+
+// ClientPool reuses clients, and with them the connections to kubernikus, between reconciles.
+// Clients are keyed by host and credentials. They are evicted once they have been idle for
+// the idle timeout or every secret they have been created from holds different credentials.
+type ClientPool struct {
+	idleTimeout time.Duration
+	defaults    TransportOptions
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+	sources map[string]string
+
+	now func() time.Time
+}
+
+type pooledClient struct {
+	client   *Client
+	lastUsed time.Time
+}
+
+// NewClientPool creates an empty ClientPool evicting clients idle for longer than idleTimeout.
+// The transport options of the clients default to defaults.
+func NewClientPool(idleTimeout time.Duration, defaults TransportOptions) *ClientPool {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultClientIdleTimeout
+	}
+	return &ClientPool{
+		idleTimeout: idleTimeout,
+		defaults:    defaults,
+		clients:     map[string]*pooledClient{},
+		sources:     map[string]string{},
+		now:         time.Now,
+	}
+}
+
+// Get returns the client for creds, creating it if there is none in the pool.
+func (p *ClientPool) Get(creds *Credentials) (*Client, error) {
+	key := creds.fingerprint()
+	now := p.now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evictIdle(now)
+	if creds.Source != "" {
+		if previous, ok := p.sources[creds.Source]; ok && previous != key {
+			p.release(creds.Source)
+		}
+		p.sources[creds.Source] = key
+	}
+
+	if pooled, ok := p.clients[key]; ok {
+		pooled.lastUsed = now
+		return pooled.client, nil
+	}
+	client, err := NewClient(creds, p.defaults)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[key] = &pooledClient{client: client, lastUsed: now}
+	return client, nil
+}
+
+// API is the APIFactory handing out the clients of the pool.
+func (p *ClientPool) API(creds *Credentials) (KubernikusAPI, error) {
+	client, err := p.Get(creds)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Len returns the number of pooled clients.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// evictIdle removes the clients which have not been used for the idle timeout.
+func (p *ClientPool) evictIdle(now time.Time) {
+	for key, pooled := range p.clients {
+		if now.Sub(pooled.lastUsed) > p.idleTimeout {
+			p.evict(key)
+		}
+	}
+}
+
+// release forgets the credentials of source. Secrets holding identical credentials share a client,
+// so it is only evicted once no other secret uses it.
+func (p *ClientPool) release(source string) {
+	key := p.sources[source]
+	delete(p.sources, source)
+	for _, k := range p.sources {
+		if k == key {
+			return
+		}
+	}
+	p.evict(key)
+}
+
+func (p *ClientPool) evict(key string) {
+	pooled, ok := p.clients[key]
+	if !ok {
+		return
+	}
+	pooled.client.Close()
+	delete(p.clients, key)
+	for source, k := range p.sources {
+		if k == key {
+			delete(p.sources, source)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"testing"
+	"time"
+)
+
+func tokenCredentials(source, token string) *Credentials {
+	return &Credentials{Source: source, Host: "kubernikus.example.com", AuthMode: AuthModeToken, Token: token}
+}
+
+func TestClientPoolReusesClients(t *testing.T) {
+	pool := NewClientPool(time.Hour, TransportOptions{})
+	first, err := pool.Get(tokenCredentials("team-a/kubernikus", "token"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := pool.Get(tokenCredentials("team-a/kubernikus", "token"))
+	shared, _ := pool.Get(tokenCredentials("team-b/kubernikus", "token"))
+	if first != second || first != shared {
+		t.Error("expected identical credentials to share a client")
+	}
+	if pool.Len() != 1 {
+		t.Errorf("expected one pooled client, got %d", pool.Len())
+	}
+}
+
+func TestClientPoolEvictsRotatedCredentials(t *testing.T) {
+	pool := NewClientPool(time.Hour, TransportOptions{})
+	old, _ := pool.Get(tokenCredentials("team-a/kubernikus", "old"))
+	rotated, _ := pool.Get(tokenCredentials("team-a/kubernikus", "new"))
+	if old == rotated {
+		t.Error("expected a new client for rotated credentials")
+	}
+	if pool.Len() != 1 {
+		t.Errorf("expected the client of the old credentials to be evicted, got %d clients", pool.Len())
+	}
+}
+
+func TestClientPoolKeepsSharedClientsOnRotation(t *testing.T) {
+	pool := NewClientPool(time.Hour, TransportOptions{})
+	shared, _ := pool.Get(tokenCredentials("team-a/kubernikus", "shared"))
+	_, _ = pool.Get(tokenCredentials("team-b/kubernikus", "shared"))
+	_, _ = pool.Get(tokenCredentials("team-a/kubernikus", "new"))
+	if pool.Len() != 2 {
+		t.Errorf("expected the client still used by team-b to be kept, got %d clients", pool.Len())
+	}
+	if got, _ := pool.Get(tokenCredentials("team-b/kubernikus", "shared")); got != shared {
+		t.Error("expected team-b to keep its client")
+	}
+
+	_, _ = pool.Get(tokenCredentials("team-b/kubernikus", "new"))
+	if pool.Len() != 1 {
+		t.Errorf("expected the client to be evicted once no secret uses it, got %d clients", pool.Len())
+	}
+}
+
+func TestClientPoolEvictsIdleClients(t *testing.T) {
+	now := time.Now()
+	pool := NewClientPool(time.Minute, TransportOptions{})
+	pool.now = func() time.Time { return now }
+
+	_, _ = pool.Get(tokenCredentials("team-a/kubernikus", "a"))
+	now = now.Add(30 * time.Second)
+	_, _ = pool.Get(tokenCredentials("team-b/kubernikus", "b"))
+	now = now.Add(45 * time.Second)
+	_, _ = pool.Get(tokenCredentials("team-b/kubernikus", "b"))
+	if pool.Len() != 1 {
+		t.Errorf("expected the idle client to be evicted, got %d clients", pool.Len())
+	}
+}
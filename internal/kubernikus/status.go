@@ -4,6 +4,8 @@
 package kubernikus
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	"github.com/sapcc/kubernikus/pkg/api/client/operations"
 	"github.com/sapcc/kubernikus/pkg/api/models"
@@ -11,10 +13,10 @@ import (
 	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
 )
 
-func (c *Client) GetKKSStatus(cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (*v1alpha1.KubernikusControlPlaneStatus, error) {
+func (c *Client) GetKKSStatus(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (*v1alpha1.KubernikusControlPlaneStatus, error) {
 	ret := &v1alpha1.KubernikusControlPlaneStatus{}
-	lcp := operations.NewListClustersParams()
-	lco, err := c.kks.Operations.ListClusters(lcp, c)
+	lcp := operations.NewListClustersParamsWithContext(ctx)
+	lco, err := c.kks.Operations.ListClusters(lcp, c.authInfo(ctx))
 	if err != nil {
 		logger.Error(err, "failed to list clusters")
 		return nil, err
@@ -22,12 +24,8 @@ func (c *Client) GetKKSStatus(cp *v1alpha1.KubernikusControlPlane, logger logr.L
 	for _, kluster := range lco.Payload {
 		if kluster.Name == cp.Name {
 			ret.Version = "v" + kluster.Status.ApiserverVersion
-			ret.Initialized = true
-			if kluster.Status.Phase == models.KlusterPhaseRunning {
-				ret.Ready = true
-			} else {
-				ret.Ready = false
-			}
+			ret.Phase = string(kluster.Status.Phase)
+			ret.Ready = kluster.Status.Phase == models.KlusterPhaseRunning
 			break
 		}
 	}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"github.com/sapcc/kubernikus/pkg/api/client/operations"
+	"github.com/sapcc/kubernikus/pkg/api/models"
+
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+// TerminateControlPlane asks kubernikus to terminate the kluster backing the control plane.
+// It returns true once the kluster is gone, callers are expected to poll until then.
+func (c *Client) TerminateControlPlane(ctx context.Context, cp *v1alpha1.KubernikusControlPlane, logger logr.Logger) (bool, error) {
+	auth := c.authInfo(ctx)
+	scp := operations.NewShowClusterParamsWithContext(ctx)
+	scp.Name = cp.Name
+	sco, err := c.kks.Operations.ShowCluster(scp, auth)
+	if err != nil {
+		var showErr *operations.ShowClusterDefault
+		if errors.As(err, &showErr) && showErr.Code() == http.StatusNotFound {
+			logger.Info("cluster is gone")
+			return true, nil
+		}
+		logger.Error(err, "failed to get cluster")
+		return false, err
+	}
+	if sco.Payload.Status.Phase == models.KlusterPhaseTerminating {
+		logger.Info("cluster is terminating")
+		return false, nil
+	}
+
+	logger.Info("terminating cluster")
+	tcp := operations.NewTerminateClusterParamsWithContext(ctx)
+	tcp.Name = cp.Name
+	_, err = c.kks.Operations.TerminateCluster(tcp, auth)
+	if err != nil {
+		var termErr *operations.TerminateClusterDefault
+		if errors.As(err, &termErr) && termErr.Code() == http.StatusNotFound {
+			logger.Info("cluster is gone")
+			return true, nil
+		}
+		logger.Error(err, "failed to terminate cluster")
+		return false, err
+	}
+	return false, nil
+}
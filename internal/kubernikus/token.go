@@ -4,25 +4,27 @@
 package kubernikus
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 // GetToken this gets a token from the kubernikus auth service
 // it needs to determine the correct url by calling the authUrl and checking for redirects
-func GetToken(username, password, connectorId, authUrl string) (string, error) {
+// the requests are sent with the transport and timeout of httpClient and are cancelled together with ctx
+func GetToken(ctx context.Context, httpClient *http.Client, username, password, connectorId, authUrl string) (string, error) {
 	var redirects = 0
-	client := http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			redirects++
-			return nil
-		},
+	client := *httpClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		redirects++
+		return nil
 	}
-	req, err := http.NewRequest(http.MethodGet, authUrl, http.NoBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, authUrl, http.NoBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to build initial request: %w", err)
 	}
@@ -45,7 +47,12 @@ func GetToken(username, password, connectorId, authUrl string) (string, error) {
 	v.Set("login", username)
 	v.Set("password", password)
 
-	resp2, err := client.PostForm(resp.Request.URL.String(), v)
+	req2, err := http.NewRequestWithContext(ctx, http.MethodPost, resp.Request.URL.String(), strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build login request: %w", err)
+	}
+	req2.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp2, err := client.Do(req2) //nolint:gosec // the login url is the redirect target of authURL
 	if err != nil {
 		return "", fmt.Errorf("failed to call %s: %w", resp.Request.URL.String(), err)
 	}
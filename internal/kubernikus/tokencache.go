@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// tokenRenewBefore is how long before its expiry a cached token is renewed.
+	tokenRenewBefore = 5 * time.Minute
+	// defaultTokenLifetime is assumed for tokens which do not tell when they expire.
+	defaultTokenLifetime = 30 * time.Minute
+)
+
+// DefaultTokenCache is the TokenCache shared by all clients of the process.
+var DefaultTokenCache = NewTokenCache()
+
+// TokenCache shares the tokens of identical credentials between clients, so reconciles
+// do not log in every time. Concurrent logins with the same credentials are merged into one.
+type TokenCache struct {
+	mu      sync.Mutex
+	tokens  map[string]*cachedToken
+	sources map[string]string
+	logins  singleflight.Group
+
+	now func() time.Time
+}
+
+// cachedToken holds the token of one set of credentials. A login only stores its token if the
+// entry it started with is still cached, so credentials dropped meanwhile do not come back.
+type cachedToken struct {
+	token   string
+	renewAt time.Time
+}
+
+// NewTokenCache creates an empty TokenCache.
+func NewTokenCache() *TokenCache {
+	return &TokenCache{
+		tokens:  map[string]*cachedToken{},
+		sources: map[string]string{},
+		now:     time.Now,
+	}
+}
+
+// token returns the cached token for the credentials identified by key or asks issuer for a new one.
+// source is the secret the credentials have been read from, the token of its previous credentials is
+// dropped once they change and no other secret holds them.
+// A login is shared by all callers waiting for it, so it is not cancelled with the ctx of the caller
+// which started it, but every caller stops waiting once its own ctx is done.
+func (c *TokenCache) token(ctx context.Context, key, source string, issuer tokenIssuer) (string, error) {
+	c.mu.Lock()
+	if source != "" {
+		if previous, ok := c.sources[source]; ok && previous != key {
+			delete(c.sources, source)
+			c.dropUnused(previous)
+		}
+		c.sources[source] = key
+	}
+	entry, ok := c.tokens[key]
+	if !ok {
+		entry = &cachedToken{}
+		c.tokens[key] = entry
+	}
+	cached := *entry
+	c.mu.Unlock()
+	if cached.token != "" && c.now().Before(cached.renewAt) {
+		return cached.token, nil
+	}
+
+	login := c.logins.DoChan(key, func() (any, error) {
+		token, expiresAt, err := issuer.issue(context.WithoutCancel(ctx))
+		if err != nil {
+			return "", err
+		}
+		if expiresAt.IsZero() {
+			expiresAt = jwtExpiry(token)
+		}
+		now := c.now()
+		if expiresAt.IsZero() {
+			expiresAt = now.Add(defaultTokenLifetime)
+		}
+		c.mu.Lock()
+		if c.tokens[key] == entry {
+			entry.token, entry.renewAt = token, renewalTime(now, expiresAt)
+		}
+		c.mu.Unlock()
+		return token, nil
+	})
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-login:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// invalidate drops the token of the credentials identified by key.
+func (c *TokenCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.tokens[key]; ok {
+		*entry = cachedToken{}
+	}
+}
+
+// release forgets the credentials identified by key and the secrets they have been read from.
+// It is called once no client uses the credentials anymore, so rotated and deleted secrets
+// do not leave entries behind.
+func (c *TokenCache) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, key)
+	for source, k := range c.sources {
+		if k == key {
+			delete(c.sources, source)
+		}
+	}
+}
+
+// dropUnused forgets the credentials identified by key unless a secret still holds them.
+func (c *TokenCache) dropUnused(key string) {
+	for _, k := range c.sources {
+		if k == key {
+			return
+		}
+	}
+	delete(c.tokens, key)
+}
+
+// renewalTime renews tokens tokenRenewBefore their expiry,
+// short lived tokens are renewed after half of their lifetime.
+func renewalTime(now, expiresAt time.Time) time.Time {
+	lifetime := expiresAt.Sub(now)
+	if lifetime > 2*tokenRenewBefore {
+		return expiresAt.Add(-tokenRenewBefore)
+	}
+	return now.Add(lifetime / 2)
+}
+
+// jwtExpiry returns the exp claim of token, or the zero time if token is not a JWT.
+// The signature is not verified, the token is only inspected to know when to renew it.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(exp), 0)
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingIssuer issues numbered tokens and counts the logins.
+type countingIssuer struct {
+	logins    atomic.Int32
+	expiresAt time.Time
+	delay     time.Duration
+}
+
+func (i *countingIssuer) issue(context.Context) (string, time.Time, error) {
+	time.Sleep(i.delay)
+	n := i.logins.Add(1)
+	return fmt.Sprintf("token-%d", n), i.expiresAt, nil
+}
+
+func jwtWithExpiry(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"controller","exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJSUzI1NiJ9." + payload + ".c2lnbmF0dXJl"
+}
+
+func TestTokenCacheSharesConcurrentLogins(t *testing.T) {
+	cache := NewTokenCache()
+	issuer := &countingIssuer{delay: 50 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := cache.token(context.Background(), "key", "default/kubernikus", issuer)
+			if err != nil || token != "token-1" {
+				t.Errorf("got %q, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := issuer.logins.Load(); n != 1 {
+		t.Errorf("expected one login, got %d", n)
+	}
+}
+
+func TestTokenCacheStopsWaitingOnCancel(t *testing.T) {
+	cache := NewTokenCache()
+	issuer := &countingIssuer{delay: 200 * time.Millisecond, expiresAt: time.Now().Add(time.Hour)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := cache.token(ctx, "key", "", issuer); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	// the login continues for the other callers and is cached
+	if token, err := cache.token(context.Background(), "key", "", issuer); err != nil || token != "token-1" {
+		t.Errorf("got %q, %v", token, err)
+	}
+	if n := issuer.logins.Load(); n != 1 {
+		t.Errorf("expected one login, got %d", n)
+	}
+}
+
+func TestTokenCacheRenewsBeforeExpiry(t *testing.T) {
+	now := time.Now()
+	cache := NewTokenCache()
+	cache.now = func() time.Time { return now }
+	issuer := &countingIssuer{expiresAt: now.Add(time.Hour)}
+
+	if token, _ := cache.token(context.Background(), "key", "", issuer); token != "token-1" {
+		t.Fatalf("unexpected token %s", token)
+	}
+	now = now.Add(54 * time.Minute)
+	if token, _ := cache.token(context.Background(), "key", "", issuer); token != "token-1" {
+		t.Errorf("expected cached token, got %s", token)
+	}
+	now = now.Add(2 * time.Minute)
+	if token, _ := cache.token(context.Background(), "key", "", issuer); token != "token-2" {
+		t.Errorf("expected renewed token, got %s", token)
+	}
+}
+
+func TestTokenCacheInvalidatesChangedSecret(t *testing.T) {
+	cache := NewTokenCache()
+	issuer := &countingIssuer{expiresAt: time.Now().Add(time.Hour)}
+
+	_, _ = cache.token(context.Background(), "old", "default/kubernikus", issuer)
+	_, _ = cache.token(context.Background(), "new", "default/kubernikus", issuer)
+	if _, ok := cache.tokens["old"]; ok {
+		t.Error("expected the token of the previous credentials to be dropped")
+	}
+	if token, _ := cache.token(context.Background(), "new", "default/kubernikus", issuer); token != "token-2" {
+		t.Errorf("expected the token of the new credentials to be cached, got %s", token)
+	}
+}
+
+func TestTokenCacheKeepsTokensOfOtherSecrets(t *testing.T) {
+	cache := NewTokenCache()
+	issuer := &countingIssuer{expiresAt: time.Now().Add(time.Hour)}
+
+	_, _ = cache.token(context.Background(), "shared", "team-a/kubernikus", issuer)
+	_, _ = cache.token(context.Background(), "shared", "team-b/kubernikus", issuer)
+	_, _ = cache.token(context.Background(), "new", "team-a/kubernikus", issuer)
+	if token, _ := cache.token(context.Background(), "shared", "team-b/kubernikus", issuer); token != "token-1" {
+		t.Errorf("expected the token still used by team-b to be kept, got %s", token)
+	}
+}
+
+func TestTokenCacheDropsLoginsOfRotatedSecrets(t *testing.T) {
+	cache := NewTokenCache()
+	slow := &countingIssuer{expiresAt: time.Now().Add(time.Hour), delay: 100 * time.Millisecond}
+	fast := &countingIssuer{expiresAt: time.Now().Add(time.Hour)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cache.token(context.Background(), "old", "default/kubernikus", slow)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	_, _ = cache.token(context.Background(), "new", "default/kubernikus", fast)
+	<-done
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if _, ok := cache.tokens["old"]; ok {
+		t.Error("expected the login of the rotated credentials not to be cached")
+	}
+}
+
+func TestTokenCacheReleasesClosedClients(t *testing.T) {
+	cache := NewTokenCache()
+	issuer := &countingIssuer{expiresAt: time.Now().Add(time.Hour)}
+	client := &Client{
+		tokens:    &cachedTokenProvider{cache: cache, key: "key", source: "default/kubernikus", issuer: issuer},
+		transport: &http.Transport{},
+	}
+
+	if _, err := client.tokens.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+	if len(cache.tokens) != 0 || len(cache.sources) != 0 {
+		t.Errorf("expected the closed client to be forgotten, got tokens %v and sources %v", cache.tokens, cache.sources)
+	}
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	if got := jwtExpiry(jwtWithExpiry(exp)); !got.Equal(exp) {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	if got := jwtExpiry("opaque-token"); !got.IsZero() {
+		t.Errorf("expected no expiry for opaque tokens, got %s", got)
+	}
+}
+
+func TestRenewalTime(t *testing.T) {
+	now := time.Now()
+	if got := renewalTime(now, now.Add(time.Hour)); !got.Equal(now.Add(55 * time.Minute)) {
+		t.Errorf("unexpected renewal of long lived token at %s", got)
+	}
+	if got := renewalTime(now, now.Add(4*time.Minute)); !got.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("unexpected renewal of short lived token at %s", got)
+	}
+}
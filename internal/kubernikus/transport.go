@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultRequestTimeout bounds requests to kubernikus and its auth services unless configured otherwise.
+const DefaultRequestTimeout = 30 * time.Second
+
+// TransportOptions configures the http connections to kubernikus and its auth services.
+// They are set controller wide by flags and can be overridden by the credentials secret.
+type TransportOptions struct {
+	// CABundle holds PEM encoded certificates trusted in addition to the system roots.
+	CABundle []byte `json:",omitempty"`
+	// InsecureSkipVerify disables the verification of server certificates, only use it for development.
+	InsecureSkipVerify bool `json:",omitempty"`
+	// ProxyURL is the http proxy used for all requests. Without it the proxy environment variables apply.
+	ProxyURL string `json:",omitempty"`
+	// NoProxy is a comma separated list of hosts reached without the ProxyURL.
+	NoProxy string `json:",omitempty"`
+	// Timeout bounds each request, including reading the response.
+	Timeout time.Duration `json:",omitempty"`
+}
+
+// withDefaults fills the options not set in o from defaults.
+// Insecure mode is enabled if either of them enables it.
+func (o TransportOptions) withDefaults(defaults TransportOptions) TransportOptions {
+	if len(o.CABundle) == 0 {
+		o.CABundle = defaults.CABundle
+	}
+	o.InsecureSkipVerify = o.InsecureSkipVerify || defaults.InsecureSkipVerify
+	if o.ProxyURL == "" {
+		o.ProxyURL = defaults.ProxyURL
+		if o.NoProxy == "" {
+			o.NoProxy = defaults.NoProxy
+		}
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaults.Timeout
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultRequestTimeout
+	}
+	return o
+}
+
+// validate checks the CA bundle and the proxy URL.
+func (o TransportOptions) validate() error {
+	if len(o.CABundle) > 0 && !x509.NewCertPool().AppendCertsFromPEM(o.CABundle) {
+		return errors.New("CA bundle does not contain any PEM encoded certificate")
+	}
+	if o.ProxyURL != "" {
+		u, err := url.Parse(o.ProxyURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return fmt.Errorf("proxy URL %q must be an absolute http, https or socks5 URL", o.ProxyURL)
+		}
+	}
+	return nil
+}
+
+// newHTTPClient creates a client with its own transport configured by o.
+func (o TransportOptions) newHTTPClient() (*http.Client, *http.Transport, error) {
+	if err := o.validate(); err != nil {
+		return nil, nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify, //nolint:gosec // opt-in for development setups
+	}
+	if len(o.CABundle) > 0 {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		roots.AppendCertsFromPEM(o.CABundle)
+		tlsConfig.RootCAs = roots
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if o.ProxyURL != "" {
+		proxy := (&httpproxy.Config{HTTPProxy: o.ProxyURL, HTTPSProxy: o.ProxyURL, NoProxy: o.NoProxy}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+
+	return &http.Client{Transport: transport, Timeout: o.Timeout}, transport, nil
+}
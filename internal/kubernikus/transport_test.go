@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernikus
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func certificatePEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func TestTransportOptionsWithDefaults(t *testing.T) {
+	defaults := TransportOptions{CABundle: []byte("default"), ProxyURL: "http://proxy:3128", NoProxy: "internal", Timeout: time.Minute}
+
+	merged := TransportOptions{}.withDefaults(defaults)
+	if string(merged.CABundle) != "default" || merged.ProxyURL != defaults.ProxyURL || merged.NoProxy != "internal" || merged.Timeout != time.Minute {
+		t.Errorf("expected the defaults, got %+v", merged)
+	}
+
+	merged = TransportOptions{ProxyURL: "http://other:3128", Timeout: time.Second}.withDefaults(defaults)
+	if merged.ProxyURL != "http://other:3128" || merged.NoProxy != "" || merged.Timeout != time.Second {
+		t.Errorf("expected the credential settings to win, got %+v", merged)
+	}
+
+	if merged := (TransportOptions{}).withDefaults(TransportOptions{}); merged.Timeout != DefaultRequestTimeout {
+		t.Errorf("expected the default timeout, got %s", merged.Timeout)
+	}
+}
+
+func TestTransportOptionsTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		name    string
+		options TransportOptions
+		trusted bool
+	}{
+		{name: "system roots", options: TransportOptions{}},
+		{name: "ca bundle", options: TransportOptions{CABundle: certificatePEM(server)}, trusted: true},
+		{name: "insecure", options: TransportOptions{InsecureSkipVerify: true}, trusted: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, err := tt.options.newHTTPClient()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if tt.trusted != (err == nil) {
+				t.Errorf("expected trusted=%t, got %v", tt.trusted, err)
+			}
+		})
+	}
+}
+
+func TestTransportOptionsProxyAndTimeout(t *testing.T) {
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer proxy.Close()
+
+	client, _, err := TransportOptions{ProxyURL: proxy.URL, Timeout: 50 * time.Millisecond}.newHTTPClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = client.Get("http://kubernikus.example.com/api/v1/clusters")
+	if err == nil {
+		t.Error("expected the request to time out")
+	}
+	if url := <-proxied; url != "http://kubernikus.example.com/api/v1/clusters" {
+		t.Errorf("unexpected proxied url %s", url)
+	}
+
+	if _, _, err := (TransportOptions{ProxyURL: "proxy:3128"}).newHTTPClient(); err == nil {
+		t.Error("expected an error for a relative proxy url")
+	}
+	if _, _, err := (TransportOptions{CABundle: []byte("not a certificate")}).newHTTPClient(); err == nil {
+		t.Error("expected an error for an invalid CA bundle")
+	}
+}
@@ -0,0 +1,346 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+// defaults as applied by kubernikus when creating a kluster
+const (
+	defaultServiceCidr   = "198.18.128.0/17"
+	defaultClusterCidr   = "100.100.0.0/16"
+	defaultAdvertisePort = 6443
+	defaultDnsDomain     = "cluster.local"
+	defaultAudit         = "stdout"
+)
+
+// klusterNameMaxLength is the maximum length of a kluster name accepted by kubernikus.
+const klusterNameMaxLength = 20
+
+// maxServiceCidrBits is the longest prefix of a service cidr, smaller networks can not hold the dns address.
+const maxServiceCidrBits = 30
+
+var (
+	klusterNameRegexp = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+	// versionRegexp follows the kubernikus version pattern, but also allows the "v" prefix used by Cluster API.
+	versionRegexp = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+)
+
+var kubernikuscontrolplanelog = logf.Log.WithName("kubernikuscontrolplane-resource")
+
+// SetupKubernikusControlPlaneWebhookWithManager registers the webhooks for KubernikusControlPlane in the manager.
+func SetupKubernikusControlPlaneWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&controlplanev1alpha1.KubernikusControlPlane{}).
+		WithValidator(&KubernikusControlPlaneCustomValidator{}).
+		WithDefaulter(&KubernikusControlPlaneCustomDefaulter{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-controlplane-cluster-x-k8s-io-v1alpha1-kubernikuscontrolplane,mutating=true,failurePolicy=fail,sideEffects=None,groups=controlplane.cluster.x-k8s.io,resources=kubernikuscontrolplanes,verbs=create;update,versions=v1alpha1,name=mkubernikuscontrolplane-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// KubernikusControlPlaneCustomDefaulter sets default values on the KubernikusControlPlane.
+type KubernikusControlPlaneCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &KubernikusControlPlaneCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the KubernikusControlPlane.
+func (d *KubernikusControlPlaneCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	kcp, ok := obj.(*controlplanev1alpha1.KubernikusControlPlane)
+	if !ok {
+		return fmt.Errorf("expected a KubernikusControlPlane object but got %T", obj)
+	}
+	kubernikuscontrolplanelog.Info("defaulting", "name", kcp.GetName())
+
+	if kcp.Spec.ServiceCidr == "" {
+		kcp.Spec.ServiceCidr = defaultServiceCidr
+	}
+	if kcp.Spec.ClusterCidr == "" {
+		kcp.Spec.ClusterCidr = defaultClusterCidr
+	}
+	if kcp.Spec.AdvertisePort == 0 {
+		kcp.Spec.AdvertisePort = defaultAdvertisePort
+	}
+	if kcp.Spec.DnsDomain == "" {
+		kcp.Spec.DnsDomain = defaultDnsDomain
+	}
+	if kcp.Spec.DnsAddress == "" {
+		if addr, ok := defaultDnsAddress(kcp.Spec.ServiceCidr); ok {
+			kcp.Spec.DnsAddress = addr.String()
+		}
+	}
+	if kcp.Spec.DeletionPolicy == "" {
+		kcp.Spec.DeletionPolicy = controlplanev1alpha1.DeletionPolicyDelete
+	}
+	if kcp.Spec.CustomCNI == nil {
+		kcp.Spec.CustomCNI = ptr.To(true)
+	}
+	if kcp.Spec.SeedKubeadm == nil {
+		kcp.Spec.SeedKubeadm = ptr.To(true)
+	}
+	if kcp.Spec.Audit == "" {
+		kcp.Spec.Audit = defaultAudit
+	}
+	if kcp.Spec.Dex == nil {
+		kcp.Spec.Dex = ptr.To(false)
+	}
+	if kcp.Spec.Dashboard == nil {
+		kcp.Spec.Dashboard = ptr.To(false)
+	}
+	return nil
+}
+
+// defaultDnsAddress returns the address kubernikus uses for cluster dns, the network address of the
+// service cidr plus two. It is false for cidrs which are malformed or too small to hold the address.
+func defaultDnsAddress(serviceCidr string) (netip.Addr, bool) {
+	prefix, err := netip.ParsePrefix(serviceCidr)
+	if err != nil || !prefix.Addr().Is4() || prefix.Bits() > maxServiceCidrBits {
+		return netip.Addr{}, false
+	}
+	addr := prefix.Masked().Addr().Next().Next()
+	return addr, prefix.Contains(addr)
+}
+
+//+kubebuilder:webhook:path=/validate-controlplane-cluster-x-k8s-io-v1alpha1-kubernikuscontrolplane,mutating=false,failurePolicy=fail,sideEffects=None,groups=controlplane.cluster.x-k8s.io,resources=kubernikuscontrolplanes,verbs=create;update,versions=v1alpha1,name=vkubernikuscontrolplane-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// KubernikusControlPlaneCustomValidator validates the KubernikusControlPlane on admission,
+// so invalid input is rejected before it is sent to kubernikus.
+type KubernikusControlPlaneCustomValidator struct{}
+
+var _ webhook.CustomValidator = &KubernikusControlPlaneCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type KubernikusControlPlane.
+func (v *KubernikusControlPlaneCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	kcp, ok := obj.(*controlplanev1alpha1.KubernikusControlPlane)
+	if !ok {
+		return nil, fmt.Errorf("expected a KubernikusControlPlane object but got %T", obj)
+	}
+	kubernikuscontrolplanelog.Info("validation for creation", "name", kcp.GetName())
+
+	return nil, validateKubernikusControlPlane(kcp)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type KubernikusControlPlane.
+func (v *KubernikusControlPlaneCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldKcp, ok := oldObj.(*controlplanev1alpha1.KubernikusControlPlane)
+	if !ok {
+		return nil, fmt.Errorf("expected a KubernikusControlPlane object for the oldObj but got %T", oldObj)
+	}
+	kcp, ok := newObj.(*controlplanev1alpha1.KubernikusControlPlane)
+	if !ok {
+		return nil, fmt.Errorf("expected a KubernikusControlPlane object for the newObj but got %T", newObj)
+	}
+	kubernikuscontrolplanelog.Info("validation for update", "name", kcp.GetName())
+
+	// control planes created before a rule existed must still get their finalizer added and removed
+	if !kcp.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldKcp.Spec, kcp.Spec) {
+		return nil, nil
+	}
+	allErrs := validateImmutableFields(&oldKcp.Spec, &kcp.Spec, field.NewPath("spec"))
+	allErrs = append(allErrs, kubernikusControlPlaneErrors(kcp)...)
+	return nil, invalid(kcp, allErrs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type KubernikusControlPlane.
+func (v *KubernikusControlPlaneCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateKubernikusControlPlane(kcp *controlplanev1alpha1.KubernikusControlPlane) error {
+	return invalid(kcp, kubernikusControlPlaneErrors(kcp))
+}
+
+func kubernikusControlPlaneErrors(kcp *controlplanev1alpha1.KubernikusControlPlane) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateKlusterName(kcp.Name, field.NewPath("metadata", "name"))...)
+	allErrs = append(allErrs, validateKubernikusControlPlaneSpec(&kcp.Spec, field.NewPath("spec"))...)
+	return allErrs
+}
+
+// invalid returns an Invalid error listing allErrs, or nil if there are none.
+func invalid(kcp *controlplanev1alpha1.KubernikusControlPlane, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(controlplanev1alpha1.GroupVersion.WithKind("KubernikusControlPlane").GroupKind(), kcp.Name, allErrs)
+}
+
+// validateImmutableFields rejects changes to fields kubernikus can not update on a running kluster,
+// the same fields the controller reports as drift.
+// A previously empty field may only be set to the value kubernikus defaulted it to, as set by the defaulting webhook.
+func validateImmutableFields(oldSpec, newSpec *controlplanev1alpha1.KubernikusControlPlaneSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	serviceCidr := oldSpec.ServiceCidr
+	if serviceCidr == "" {
+		serviceCidr = defaultServiceCidr
+	}
+	var dnsAddress string
+	if addr, ok := defaultDnsAddress(serviceCidr); ok {
+		dnsAddress = addr.String()
+	}
+	immutable := []struct {
+		name      string
+		old, new  string
+		defaulted string
+	}{
+		{"serviceCidr", oldSpec.ServiceCidr, newSpec.ServiceCidr, defaultServiceCidr},
+		{"clusterCidr", oldSpec.ClusterCidr, newSpec.ClusterCidr, defaultClusterCidr},
+		{"dnsDomain", oldSpec.DnsDomain, newSpec.DnsDomain, defaultDnsDomain},
+		{"dnsAddress", oldSpec.DnsAddress, newSpec.DnsAddress, dnsAddress},
+		{"backup", oldSpec.Backup, newSpec.Backup, ""},
+	}
+	for _, f := range immutable {
+		if f.old != f.new && (f.old != "" || f.new != f.defaulted) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(f.name), f.new, "field is immutable"))
+		}
+	}
+	immutableFlags := []struct {
+		name     string
+		old, new *bool
+	}{
+		{"customCNI", oldSpec.CustomCNI, newSpec.CustomCNI},
+		{"seedKubeadm", oldSpec.SeedKubeadm, newSpec.SeedKubeadm},
+	}
+	for _, f := range immutableFlags {
+		if f.old != nil && (f.new == nil || *f.old != *f.new) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(f.name), f.new, "field is immutable"))
+		}
+	}
+	return allErrs
+}
+
+func validateKlusterName(name string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(name) > klusterNameMaxLength {
+		allErrs = append(allErrs, field.TooLong(fldPath, name, klusterNameMaxLength))
+	}
+	if !klusterNameRegexp.MatchString(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, "must consist of lower case alphanumeric characters or '-', start with a letter and end with an alphanumeric character"))
+	}
+	return allErrs
+}
+
+func validateKubernikusControlPlaneSpec(spec *controlplanev1alpha1.KubernikusControlPlaneSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.Version == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("version"), "a kubernetes version is required"))
+	} else if !versionRegexp.MatchString(spec.Version) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), spec.Version, "must be a semantic version like 1.31.2 or v1.31.2"))
+	}
+
+	if ref := spec.CredentialsRef; ref != nil {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("credentialsRef", "name"), "a secret name is required"))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(ref.Name) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("credentialsRef", "name"), ref.Name, msg))
+			}
+		}
+	}
+
+	if spec.CredentialsRef != nil && spec.IdentityRef != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("identityRef"), "can not be combined with credentialsRef"))
+	}
+
+	var serviceNet, clusterNet *net.IPNet
+	if spec.ServiceCidr != "" {
+		var err error
+		_, serviceNet, err = net.ParseCIDR(spec.ServiceCidr)
+		if err != nil || serviceNet.IP.To4() == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("serviceCidr"), spec.ServiceCidr, "must be an IPv4 CIDR"))
+			serviceNet = nil
+		} else if ones, _ := serviceNet.Mask.Size(); ones > maxServiceCidrBits {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("serviceCidr"), spec.ServiceCidr,
+				fmt.Sprintf("must be a /%d or larger network", maxServiceCidrBits)))
+		}
+	}
+	if spec.ClusterCidr != "" {
+		var err error
+		_, clusterNet, err = net.ParseCIDR(spec.ClusterCidr)
+		if err != nil || clusterNet.IP.To4() == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("clusterCidr"), spec.ClusterCidr, "must be an IPv4 CIDR"))
+			clusterNet = nil
+		}
+	}
+	if serviceNet != nil && clusterNet != nil && (serviceNet.Contains(clusterNet.IP) || clusterNet.Contains(serviceNet.IP)) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("clusterCidr"), spec.ClusterCidr, "must not overlap with serviceCidr "+spec.ServiceCidr))
+	}
+
+	if spec.AdvertiseAddress != "" && net.ParseIP(spec.AdvertiseAddress).To4() == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("advertiseAddress"), spec.AdvertiseAddress, "must be an IPv4 address"))
+	}
+	if spec.AdvertisePort < 1 || spec.AdvertisePort > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("advertisePort"), spec.AdvertisePort, "must be between 1 and 65535"))
+	}
+
+	if spec.DnsAddress != "" {
+		dnsIP := net.ParseIP(spec.DnsAddress).To4()
+		switch {
+		case dnsIP == nil:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dnsAddress"), spec.DnsAddress, "must be an IPv4 address"))
+		case serviceNet != nil && !serviceNet.Contains(dnsIP):
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dnsAddress"), spec.DnsAddress, "must be part of serviceCidr "+spec.ServiceCidr))
+		}
+	}
+	if spec.DnsDomain != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.DnsDomain) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dnsDomain"), spec.DnsDomain, msg))
+		}
+	}
+
+	if spec.Oidc != nil {
+		allErrs = append(allErrs, validateOIDC(spec.Oidc, fldPath.Child("oidc"))...)
+	}
+
+	// kubernikus requires dex for the dashboard and does not allow a custom oidc configuration next to dex
+	dex := spec.Dex != nil && *spec.Dex
+	if spec.Dashboard != nil && *spec.Dashboard && !dex {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("dashboard"), *spec.Dashboard, "requires dex to be enabled"))
+	}
+	if dex && spec.Oidc != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("oidc"), "can not be configured while dex is enabled"))
+	}
+
+	return allErrs
+}
+
+func validateOIDC(oidc *controlplanev1alpha1.OIDC, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if oidc.IssuerURL == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("issuerURL"), "an issuer URL is required when oidc is configured"))
+	} else {
+		u, err := url.Parse(oidc.IssuerURL)
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("issuerURL"), oidc.IssuerURL, err.Error()))
+		case u.Scheme != "https" || u.Host == "":
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("issuerURL"), oidc.IssuerURL, "must be an absolute https URL"))
+		case u.RawQuery != "" || u.Fragment != "":
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("issuerURL"), oidc.IssuerURL, "must not contain a query or fragment"))
+		}
+	}
+	if oidc.ClientID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clientID"), "a client ID is required when oidc is configured"))
+	}
+	return allErrs
+}
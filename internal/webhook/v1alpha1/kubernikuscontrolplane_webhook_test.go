@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
+)
+
+var _ = Describe("KubernikusControlPlane Webhook", func() {
+	var (
+		kcp       *controlplanev1alpha1.KubernikusControlPlane
+		validator KubernikusControlPlaneCustomValidator
+		defaulter KubernikusControlPlaneCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		kcp = &controlplanev1alpha1.KubernikusControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       controlplanev1alpha1.KubernikusControlPlaneSpec{Version: "1.31.2"},
+		}
+	})
+
+	Context("When creating KubernikusControlPlane under Defaulting Webhook", func() {
+		It("Should apply the kubernikus defaults", func() {
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			Expect(kcp.Spec.ServiceCidr).To(Equal("198.18.128.0/17"))
+			Expect(kcp.Spec.ClusterCidr).To(Equal("100.100.0.0/16"))
+			Expect(kcp.Spec.AdvertisePort).To(Equal(int64(6443)))
+			Expect(kcp.Spec.DnsDomain).To(Equal("cluster.local"))
+			Expect(kcp.Spec.DnsAddress).To(Equal("198.18.128.2"))
+			Expect(kcp.Spec.DeletionPolicy).To(Equal(controlplanev1alpha1.DeletionPolicyDelete))
+			Expect(kcp.Spec.CustomCNI).To(HaveValue(BeTrue()))
+			Expect(kcp.Spec.SeedKubeadm).To(HaveValue(BeTrue()))
+			Expect(kcp.Spec.Audit).To(Equal("stdout"))
+			Expect(kcp.Spec.Dex).To(HaveValue(BeFalse()))
+			Expect(kcp.Spec.Dashboard).To(HaveValue(BeFalse()))
+		})
+
+		It("Should keep explicitly disabled flags", func() {
+			kcp.Spec.CustomCNI = ptr.To(false)
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			Expect(kcp.Spec.CustomCNI).To(HaveValue(BeFalse()))
+		})
+
+		It("Should derive the dns address from a custom service cidr", func() {
+			kcp.Spec.ServiceCidr = "10.96.0.0/12"
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			Expect(kcp.Spec.DnsAddress).To(Equal("10.96.0.2"))
+		})
+
+		It("Should derive the dns address from the network of the service cidr", func() {
+			kcp.Spec.ServiceCidr = "10.0.0.7/30"
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			Expect(kcp.Spec.DnsAddress).To(Equal("10.0.0.6"))
+		})
+
+		It("Should not derive a dns address outside of a small service cidr", func() {
+			kcp.Spec.ServiceCidr = "10.0.0.254/31"
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			Expect(kcp.Spec.DnsAddress).To(BeEmpty())
+		})
+	})
+
+	Context("When creating or updating KubernikusControlPlane under Validating Webhook", func() {
+		It("Should admit a defaulted control plane", func() {
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			_, err := validator.ValidateCreate(context.Background(), kcp)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should accept versions with a v prefix", func() {
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			kcp.Spec.Version = "v1.31.2"
+			_, err := validator.ValidateCreate(context.Background(), kcp)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("Should deny invalid input",
+			func(mutate func(*controlplanev1alpha1.KubernikusControlPlane), field string) {
+				mutate(kcp)
+				_, err := validator.ValidateCreate(context.Background(), kcp)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(field))
+			},
+			Entry("malformed version", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.Version = "1.31"
+			}, "spec.version"),
+			Entry("malformed service cidr", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.ServiceCidr = "198.18.128.0"
+			}, "spec.serviceCidr"),
+			Entry("overlapping cidrs", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.ServiceCidr = "10.0.0.0/16"
+				kcp.Spec.ClusterCidr = "10.0.0.0/8"
+			}, "spec.clusterCidr"),
+			Entry("advertise port out of range", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.AdvertisePort = 70000
+			}, "spec.advertisePort"),
+			Entry("advertise port zero", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.AdvertisePort = 0
+			}, "spec.advertisePort"),
+			Entry("service cidr narrower than /30", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.ServiceCidr = "10.0.0.254/31"
+			}, "spec.serviceCidr"),
+			Entry("dns address outside of the service cidr", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.ServiceCidr = "198.18.128.0/17"
+				kcp.Spec.DnsAddress = "10.0.0.10"
+			}, "spec.dnsAddress"),
+			Entry("malformed dns domain", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.DnsDomain = "Cluster_Local"
+			}, "spec.dnsDomain"),
+			Entry("insecure oidc issuer", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.Oidc = &controlplanev1alpha1.OIDC{IssuerURL: "http://issuer.example.com", ClientID: "kubernetes"}
+			}, "spec.oidc.issuerURL"),
+			Entry("oidc without client id", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.Oidc = &controlplanev1alpha1.OIDC{IssuerURL: "https://issuer.example.com"}
+			}, "spec.oidc.clientID"),
+			Entry("dashboard without dex", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.Dashboard = ptr.To(true)
+			}, "spec.dashboard"),
+			Entry("oidc next to dex", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.Dex = ptr.To(true)
+				kcp.Spec.Oidc = &controlplanev1alpha1.OIDC{IssuerURL: "https://issuer.example.com", ClientID: "kubernetes"}
+			}, "spec.oidc"),
+			Entry("kluster name too long", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Name = "a-very-long-kluster-name"
+			}, "metadata.name"),
+			Entry("kluster name with invalid characters", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Name = "1st.kluster"
+			}, "metadata.name"),
+			Entry("credentials ref without name", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.CredentialsRef = &corev1.LocalObjectReference{}
+			}, "spec.credentialsRef.name"),
+			Entry("credentials ref with invalid name", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.CredentialsRef = &corev1.LocalObjectReference{Name: "Kubernikus_Credentials"}
+			}, "spec.credentialsRef.name"),
+			Entry("credentials ref next to identity ref", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.CredentialsRef = &corev1.LocalObjectReference{Name: "kubernikus-credentials"}
+				kcp.Spec.IdentityRef = &controlplanev1alpha1.KubernikusIdentityReference{Name: "platform"}
+			}, "spec.identityRef"),
+		)
+	})
+
+	Context("When updating KubernikusControlPlane under Validating Webhook", func() {
+		var oldKcp *controlplanev1alpha1.KubernikusControlPlane
+
+		BeforeEach(func() {
+			Expect(defaulter.Default(context.Background(), kcp)).To(Succeed())
+			oldKcp = kcp.DeepCopy()
+		})
+
+		It("Should allow changing mutable fields", func() {
+			kcp.Spec.Version = "1.32.0"
+			kcp.Spec.SSHPublicKey = "ssh-ed25519 AAAA"
+			_, err := validator.ValidateUpdate(context.Background(), oldKcp, kcp)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should allow setting previously empty fields to their defaults", func() {
+			oldKcp.Spec.ServiceCidr = ""
+			oldKcp.Spec.DnsDomain = ""
+			oldKcp.Spec.DnsAddress = ""
+			_, err := validator.ValidateUpdate(context.Background(), oldKcp, kcp)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny setting previously empty fields to other values", func() {
+			oldKcp.Spec.ClusterCidr = ""
+			oldKcp.Spec.DnsAddress = ""
+			kcp.Spec.ClusterCidr = "100.101.0.0/16"
+			kcp.Spec.DnsAddress = "198.18.128.10"
+			_, err := validator.ValidateUpdate(context.Background(), oldKcp, kcp)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.clusterCidr"))
+			Expect(err.Error()).To(ContainSubstring("spec.dnsAddress"))
+		})
+
+		It("Should admit metadata changes of control planes breaking newer rules", func() {
+			oldKcp.Name = "a-name-longer-than-the-limit"
+			oldKcp.Spec.AdvertisePort = 0
+			kcp = oldKcp.DeepCopy()
+			kcp.Finalizers = []string{controlplanev1alpha1.KubernikusControlPlaneFinalizer}
+			_, err := validator.ValidateUpdate(context.Background(), oldKcp, kcp)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should admit updates of deleted control planes", func() {
+			kcp.DeletionTimestamp = ptr.To(metav1.Now())
+			kcp.Spec.AdvertisePort = 0
+			_, err := validator.ValidateUpdate(context.Background(), oldKcp, kcp)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should report immutable and invalid fields together", func() {
+			kcp.Spec.ClusterCidr = "100.101.0.0/16"
+			kcp.Spec.AdvertisePort = 0
+			_, err := validator.ValidateUpdate(context.Background(), oldKcp, kcp)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.clusterCidr"))
+			Expect(err.Error()).To(ContainSubstring("spec.advertisePort"))
+		})
+
+		DescribeTable("Should deny changing immutable fields",
+			func(mutate func(*controlplanev1alpha1.KubernikusControlPlane), field string) {
+				mutate(kcp)
+				_, err := validator.ValidateUpdate(context.Background(), oldKcp, kcp)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(field))
+				Expect(err.Error()).To(ContainSubstring("field is immutable"))
+			},
+			Entry("service cidr", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.ServiceCidr = "198.19.0.0/17"
+			}, "spec.serviceCidr"),
+			Entry("cluster cidr", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.ClusterCidr = "100.101.0.0/16"
+			}, "spec.clusterCidr"),
+			Entry("dns domain", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.DnsDomain = "example.local"
+			}, "spec.dnsDomain"),
+			Entry("dns address", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.DnsAddress = "198.18.128.10"
+			}, "spec.dnsAddress"),
+			Entry("backup", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.Backup = "off"
+			}, "spec.backup"),
+			Entry("custom cni", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.CustomCNI = ptr.To(false)
+			}, "spec.customCNI"),
+			Entry("seed kubeadm", func(kcp *controlplanev1alpha1.KubernikusControlPlane) {
+				kcp.Spec.SeedKubeadm = ptr.To(false)
+			}, "spec.seedKubeadm"),
+		)
+	})
+})
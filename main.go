@@ -5,7 +5,10 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -19,9 +22,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	controlplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/api/v1alpha1"
 	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/controller"
+	"github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/kubernikus"
+	webhookcontrolplanev1alpha1 "github.com/sapcc/cluster-api-control-plane-provider-kubernikus/internal/webhook/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -42,8 +48,48 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var webhookPort int
+	var webhookCertDir string
+	var identityNamespace string
+	var clientIdleTimeout time.Duration
+	var kubernikusCAFile string
+	var transportDefaults kubernikus.TransportOptions
+	var requeueIntervals controller.RequeueIntervals
+	var watchFilterValue string
+	var tokenFileDirs string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server listens on.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+		"The directory containing the tls.crt and tls.key of the webhook server.")
+	flag.StringVar(&identityNamespace, "identity-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace holding the credentials secrets of KubernikusIdentities, defaults to the namespace of the controller.")
+	flag.DurationVar(&clientIdleTimeout, "kubernikus-client-idle-timeout", kubernikus.DefaultClientIdleTimeout,
+		"How long an unused kubernikus client and its connections are kept.")
+	flag.StringVar(&kubernikusCAFile, "kubernikus-ca-file", "",
+		"A PEM encoded CA bundle trusted for kubernikus and its auth services, in addition to the system roots.")
+	flag.BoolVar(&transportDefaults.InsecureSkipVerify, "kubernikus-insecure-skip-tls-verify", false,
+		"Skip the verification of kubernikus server certificates. Only use this for development.")
+	flag.StringVar(&transportDefaults.ProxyURL, "kubernikus-proxy-url", "",
+		"The http proxy used to reach kubernikus, defaults to the proxy environment variables.")
+	flag.StringVar(&transportDefaults.NoProxy, "kubernikus-no-proxy", "",
+		"A comma separated list of hosts reached without the kubernikus proxy.")
+	flag.DurationVar(&transportDefaults.Timeout, "kubernikus-timeout", kubernikus.DefaultRequestTimeout,
+		"The timeout of each request to kubernikus and its auth services.")
+	flag.StringVar(&tokenFileDirs, "kubernikus-token-file-dirs", "",
+		"A comma separated list of directories the token-file auth mode of KubernikusIdentities may read tokens from. The mode is disabled if empty.")
+	flag.DurationVar(&requeueIntervals.Provisioning, "requeue-interval-provisioning", controller.DefaultRequeueIntervals.Provisioning,
+		"How often a control plane is reconciled while its kluster is pending or creating.")
+	flag.DurationVar(&requeueIntervals.Upgrading, "requeue-interval-upgrading", controller.DefaultRequeueIntervals.Upgrading,
+		"How often a control plane is reconciled while its kluster is upgrading.")
+	flag.DurationVar(&requeueIntervals.Terminating, "requeue-interval-terminating", controller.DefaultRequeueIntervals.Terminating,
+		"How often a deleted control plane is reconciled while its kluster is terminating.")
+	flag.DurationVar(&requeueIntervals.Running, "requeue-interval-running", controller.DefaultRequeueIntervals.Running,
+		"How often a control plane is reconciled while its kluster is running.")
+	flag.DurationVar(&requeueIntervals.KubeconfigRotation, "kubeconfig-rotation-before", controller.DefaultRequeueIntervals.KubeconfigRotation,
+		"How long before its client certificate expires the kubeconfig of a cluster is rotated.")
+	flag.StringVar(&watchFilterValue, "watch-filter", "",
+		fmt.Sprintf("Label value that the controller watches to reconcile cluster-api objects. Label key is always %s. If unspecified, the controller watches for all cluster-api objects.", capiv1beta1.WatchLabel))
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -55,12 +101,27 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if kubernikusCAFile != "" {
+		caBundle, err := os.ReadFile(kubernikusCAFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read kubernikus CA bundle")
+			os.Exit(1)
+		}
+		transportDefaults.CABundle = caBundle
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  controller.CacheOptions(),
+		Client:                 controller.ClientOptions(),
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "4b55842f.cluster.x-k8s.io",
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
+		LeaderElection:   enableLeaderElection,
+		LeaderElectionID: "4b55842f.cluster.x-k8s.io",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -79,12 +140,25 @@ func main() {
 	}
 
 	if err = (&controller.KubernikusControlPlaneReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("kubernikuscontrolplane-controller"),
+
+		IdentityNamespace: identityNamespace,
+		Clients:           kubernikus.NewClientPool(clientIdleTimeout, transportDefaults),
+		RequeueIntervals:  requeueIntervals,
+		TokenFileDirs:     splitList(tokenFileDirs),
+		WatchFilterValue:  watchFilterValue,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubernikusControlPlane")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookcontrolplanev1alpha1.SetupKubernikusControlPlaneWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KubernikusControlPlane")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -102,3 +176,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits a comma separated flag value, ignoring empty elements.
+func splitList(value string) []string {
+	var ret []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}